@@ -0,0 +1,239 @@
+// Package cache stores processed images on disk so that they can be reused between runs
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Cache is a content-addressed directory, entries being stored in files named after their key
+type Cache struct {
+	Dir string
+}
+
+// Stats are statistics about the entries of a cache
+type Stats struct {
+	Entries int
+	Size    int64
+	// Oldest and Newest are the last time the least and the most recently used entries have been used
+	Oldest time.Time
+	Newest time.Time
+}
+
+// DefaultDir returns the default cache directory, in the user cache directory of the OS
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gopicto", "images"), nil
+}
+
+// Key returns the key of an entry identified by parts (e.g. the hash of a file and the parameters used to process it)
+func Key(parts ...interface{}) string {
+	h := sha256.New()
+	for _, p := range parts {
+		_, _ = fmt.Fprintf(h, "%v\x00", p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Hash returns the hash of some content to be used as part of a key
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// path returns the file of an entry, entries being spread in sub directories to keep directories small
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key[:2], key)
+}
+
+// Get returns the content of an entry and marks it as used, ok being false if there is no such entry
+func (c *Cache) Get(key string) (data []byte, ok bool) {
+	p := c.path(key)
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(p, now, now)
+	return data, true
+}
+
+// Put stores the content of an entry
+func (c *Cache) Put(key string, data []byte) error {
+	p := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	if err := c.mark(); err != nil {
+		return err
+	}
+
+	// Writing to a temporary file first so that an entry is never read partially written
+	tmp, err := ioutil.TempFile(filepath.Dir(p), key+".*"+tempSuffix)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+const (
+	// markerFile identifies a cache directory, following the Cache Directory Tagging Specification
+	markerFile    = "CACHEDIR.TAG"
+	markerContent = "Signature: 8a477f597d28d172789f06886806bc55\n# This file is a cache directory tag created by gopicto.\n"
+
+	tempSuffix = ".tmp"
+	// staleTempAge is the age after which a temporary file is considered left by an interrupted Put
+	staleTempAge = time.Hour
+)
+
+var (
+	dirRegexp   = regexp.MustCompile(`^[0-9a-f]{2}$`)
+	entryRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	tempRegexp  = regexp.MustCompile(`^[0-9a-f]{64}\..*\` + tempSuffix + `$`)
+)
+
+// mark writes the marker file of the cache if missing
+func (c *Cache) mark() error {
+	p := filepath.Join(c.Dir, markerFile)
+	if _, err := os.Stat(p); err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return ioutil.WriteFile(p, []byte(markerContent), 0644)
+}
+
+// isMarked returns whether dir has been written by Put, false if the cache does not exist
+func (c *Cache) isMarked() (bool, error) {
+	_, err := os.Stat(filepath.Join(c.Dir, markerFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// walk calls fn for each file laid out as path writes entries, temp telling whether it is a temporary file of Put.
+// Any other file is ignored.
+func (c *Cache) walk(fn func(path string, info fs.FileInfo, temp bool) error) error {
+	dirs, err := ioutil.ReadDir(c.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() || !dirRegexp.MatchString(dir.Name()) {
+			continue
+		}
+		files, err := ioutil.ReadDir(filepath.Join(c.Dir, dir.Name()))
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if !f.Mode().IsRegular() || !strings.HasPrefix(f.Name(), dir.Name()) {
+				continue
+			}
+			temp := tempRegexp.MatchString(f.Name())
+			if !temp && !entryRegexp.MatchString(f.Name()) {
+				continue
+			}
+			if err := fn(filepath.Join(c.Dir, dir.Name(), f.Name()), f, temp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Stats returns statistics about the entries of the cache
+func (c *Cache) Stats() (Stats, error) {
+	s := Stats{}
+	err := c.walk(func(path string, info fs.FileInfo, temp bool) error {
+		if temp {
+			return nil
+		}
+		s.Entries++
+		s.Size += info.Size()
+		if s.Oldest.IsZero() || info.ModTime().Before(s.Oldest) {
+			s.Oldest = info.ModTime()
+		}
+		if info.ModTime().After(s.Newest) {
+			s.Newest = info.ModTime()
+		}
+		return nil
+	})
+	return s, err
+}
+
+// Prune removes the entries which have not been used for olderThan (all of them if olderThan is 0)
+// and returns statistics about the entries removed.
+// Temporary files left by interrupted writes are removed as well once stale.
+// The directory is only pruned if it has been created by Put, to never remove files from another directory.
+func (c *Cache) Prune(olderThan time.Duration) (Stats, error) {
+	removed := Stats{}
+	if _, err := os.Stat(c.Dir); errors.Is(err, fs.ErrNotExist) {
+		return removed, nil
+	}
+	marked, err := c.isMarked()
+	if err != nil {
+		return removed, err
+	}
+	if !marked {
+		return removed, fmt.Errorf("%s does not look like a gopicto cache (no %s file)", c.Dir, markerFile)
+	}
+
+	now := time.Now()
+	err = c.walk(func(path string, info fs.FileInfo, temp bool) error {
+		if temp {
+			if now.Sub(info.ModTime()) < staleTempAge {
+				return nil
+			}
+			return os.Remove(path)
+		}
+		if olderThan > 0 && now.Sub(info.ModTime()) < olderThan {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed.Entries++
+		removed.Size += info.Size()
+		return nil
+	})
+	return removed, err
+}
+
+// HumanSize formats a size in bytes (e.g. 12.3MiB)
+func HumanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	c := &Cache{Dir: t.TempDir()}
+	key := Key("picture", Hash([]byte("image")), 90)
+	if key != Key("picture", Hash([]byte("image")), 90) {
+		t.Fatalf("Key() is not stable")
+	}
+	if key == Key("picture", Hash([]byte("image")), 80) {
+		t.Fatalf("Key() does not depend on all its parts")
+	}
+
+	if _, ok := c.Get(key); ok {
+		t.Errorf("Get() found an entry in an empty cache")
+	}
+	if err := c.Put(key, []byte("data")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got, ok := c.Get(key); !ok || string(got) != "data" {
+		t.Errorf("Get() = %s, %v, want data, true", got, ok)
+	}
+
+	other := Key("other")
+	if err := c.Put(other, []byte("old entry")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(c.path(other), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files not laid out as entries are not part of the cache
+	for _, name := range []string{"notes.txt", key[:2] + "/notes.txt", "zz/" + key} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(c.Dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(c.Dir, name), []byte("unrelated"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stale := c.path(other) + ".123.tmp"
+	if err := ioutil.WriteFile(stale, []byte("partial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := c.Stats()
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if s.Entries != 2 || s.Size != int64(len("data")+len("old entry")) {
+		t.Errorf("Stats() = %+v, want 2 entries of %d bytes", s, len("data")+len("old entry"))
+	}
+
+	removed, err := c.Prune(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if removed.Entries != 1 {
+		t.Errorf("Prune() removed %d entries, want 1", removed.Entries)
+	}
+	if _, ok := c.Get(key); !ok {
+		t.Errorf("Prune() removed an entry used recently")
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Prune() did not remove a stale temporary file")
+	}
+
+	if removed, err = c.Prune(0); err != nil || removed.Entries != 1 {
+		t.Errorf("Prune(0) removed %d entries (error %v), want 1", removed.Entries, err)
+	}
+	if _, err := os.Stat(filepath.Join(c.Dir, key[:2], "notes.txt")); err != nil {
+		t.Errorf("Prune(0) removed a file which is not an entry: %v", err)
+	}
+}
+
+func TestCache_Prune_notACache(t *testing.T) {
+	dir := t.TempDir()
+	key := Key("entry")
+	if err := os.MkdirAll(filepath.Join(dir, key[:2]), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, key[:2], key), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Cache{Dir: dir}
+	if _, err := c.Prune(0); err == nil {
+		t.Errorf("Prune() expected an error for a directory without the %s file", markerFile)
+	}
+	if _, err := os.Stat(filepath.Join(dir, key[:2], key)); err != nil {
+		t.Errorf("Prune() removed a file from a directory which is not a cache: %v", err)
+	}
+}
+
+func TestCache_missingDir(t *testing.T) {
+	c := &Cache{Dir: t.TempDir() + "/missing"}
+	if s, err := c.Stats(); err != nil || s.Entries != 0 {
+		t.Errorf("Stats() = %+v, %v, want no entry and no error", s, err)
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/nmaupu/gopicto/cache"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+const (
+	OlderThanFlag = "older-than"
+
+	defaultPruneOlderThan = 30 * 24 * time.Hour
+)
+
+var (
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cache of processed images",
+	}
+
+	cacheStatsCmd = &cobra.Command{
+		Use:          "stats",
+		Short:        "Print statistics about the cache of processed images",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cacheStatsCmdFunc(cmd)
+		},
+	}
+
+	cachePruneCmd = &cobra.Command{
+		Use:          "prune",
+		Short:        "Remove images which have not been used for some time from the cache",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cachePruneCmdFunc()
+		},
+	}
+
+	cacheCmdDir    string
+	pruneOlderThan time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatsCmd, cachePruneCmd)
+	cacheCmd.PersistentFlags().StringVar(&cacheCmdDir, CacheDirFlag, "", "Cache directory (default to the user cache directory)")
+	cachePruneCmd.Flags().DurationVar(&pruneOlderThan, OlderThanFlag, defaultPruneOlderThan, "Remove images not used for this duration, 0 to remove everything")
+}
+
+// newCache returns the cache using dir, the default cache directory if dir is empty
+func newCache(dir string) (*cache.Cache, error) {
+	if dir == "" {
+		d, err := cache.DefaultDir()
+		if err != nil {
+			return nil, fmt.Errorf("unable to find the cache directory, use --%s: %w", CacheDirFlag, err)
+		}
+		dir = d
+	}
+	return &cache.Cache{Dir: dir}, nil
+}
+
+func cacheStatsCmdFunc(cmd *cobra.Command) error {
+	c, err := newCache(cacheCmdDir)
+	if err != nil {
+		return err
+	}
+	s, err := c.Stats()
+	if err != nil {
+		return fmt.Errorf("unable to read cache %s: %w", c.Dir, err)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Directory: %s\n", c.Dir)
+	fmt.Fprintf(out, "Images:    %d\n", s.Entries)
+	fmt.Fprintf(out, "Size:      %s\n", cache.HumanSize(s.Size))
+	if s.Entries > 0 {
+		fmt.Fprintf(out, "Last used: %s (least recently used: %s)\n", s.Newest.Format(time.RFC3339), s.Oldest.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func cachePruneCmdFunc() error {
+	c, err := newCache(cacheCmdDir)
+	if err != nil {
+		return err
+	}
+	removed, err := c.Prune(pruneOlderThan)
+	if err != nil {
+		return fmt.Errorf("unable to prune cache %s: %w", c.Dir, err)
+	}
+
+	log.Info().
+		Str("dir", c.Dir).
+		Int("images", removed.Entries).
+		Str("freed", cache.HumanSize(removed.Size)).
+		Msg("Cache pruned successfully")
+	return nil
+}
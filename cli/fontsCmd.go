@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/nmaupu/gopicto/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fontsCmd = &cobra.Command{
+		Use:   "fonts",
+		Short: "Manage the fonts bundled with gopicto",
+	}
+
+	fontsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "Print the names of the bundled fonts, which can be used instead of a path in fonts options (e.g. text.font)",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fontsListCmdFunc(cmd)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(fontsCmd)
+	fontsCmd.AddCommand(fontsListCmd)
+}
+
+func fontsListCmdFunc(cmd *cobra.Command) {
+	out := cmd.OutOrStdout()
+	for _, name := range config.FontNames() {
+		if name == config.DefaultFont {
+			fmt.Fprintf(out, "%s (default)\n", name)
+			continue
+		}
+		fmt.Fprintln(out, name)
+	}
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nmaupu/gopicto/config"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+)
+
+const (
+	FromDirFlag = "from-dir"
+	GlobFlag    = "glob"
+	ForceFlag   = "force"
+
+	defaultInitCols  = 3
+	defaultInitLines = 3
+)
+
+var (
+	initCmd = &cobra.Command{
+		Use:          "init",
+		Short:        "Generate a configuration file from a directory of images",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return initCmdFunc()
+		},
+	}
+
+	initFromDir string
+	initGlob    string
+	initOutFile string
+	initForce   bool
+)
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringVarP(&initFromDir, FromDirFlag, "d", "", "Directory to scan recursively for images")
+	initCmd.Flags().StringVarP(&initGlob, GlobFlag, "g", "", "Only keep images whose file name matches this glob (e.g. \"*.png\")")
+	initCmd.Flags().StringVarP(&initOutFile, OutputFlag, "o", defaultConfigFile, "Specify the name of the config file generated")
+	initCmd.Flags().BoolVarP(&initForce, ForceFlag, "f", false, "Overwrite the config file if it already exists")
+	_ = initCmd.MarkFlagRequired(FromDirFlag)
+}
+
+func initCmdFunc() error {
+	if _, err := os.Stat(initOutFile); err == nil && !initForce {
+		return fmt.Errorf("%s already exists, use --%s to overwrite it", initOutFile, ForceFlag)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	iws, err := config.ImageWordsFromDir(initFromDir, initGlob)
+	if err != nil {
+		return err
+	}
+	if len(iws) == 0 {
+		return fmt.Errorf("no image found in %s", initFromDir)
+	}
+
+	cfg := config.PDF{
+		Page: config.Page{
+			Size:        config.DefaultPageSize,
+			Cols:        defaultInitCols,
+			Lines:       defaultInitLines,
+			Orientation: config.Landscape,
+		},
+		ImageWords: iws,
+	}
+
+	file, err := os.Create(initOutFile)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %w", initOutFile, err)
+	}
+	defer func(file *os.File) {
+		err := file.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("unable to close file")
+		}
+	}(file)
+
+	encoder := yaml.NewEncoder(file)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return fmt.Errorf("unable to write %s: %w", initOutFile, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("unable to write %s: %w", initOutFile, err)
+	}
+
+	log.Info().
+		Str("file", initOutFile).
+		Int("images", len(iws)).
+		Msg("Config written successfully")
+	return nil
+}
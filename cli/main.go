@@ -12,9 +12,19 @@ var (
 const (
 	AppName = "gopicto"
 
-	ConfigFlag   = "config"
-	OutputFlag   = "output"
-	CutLinesFlag = "cutLines"
+	ConfigFlag     = "config"
+	OutputFlag     = "output"
+	CutLinesFlag   = "cutLines"
+	FormatFlag     = "format"
+	DPIFlag        = "dpi"
+	LinkImagesFlag = "link-images"
+	CacheDirFlag   = "cache-dir"
+	NoCacheFlag    = "no-cache"
+	WorkersFlag    = "workers"
+	GrayscaleFlag  = "grayscale"
+	InkSaverFlag   = "ink-saver"
+	BrightnessFlag = "brightness"
+	ContrastFlag   = "contrast"
 )
 
 var rootCmd = &cobra.Command{
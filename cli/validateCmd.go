@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nmaupu/gopicto/config"
+	"github.com/nmaupu/gopicto/render"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:          "validate",
+	Short:        "Check a configuration and report all the problems found before rendering",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return validateCmdFunc(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVarP(&cfgFile, ConfigFlag, "c", defaultConfigFile, "Config file to use")
+}
+
+func validateCmdFunc(cmd *cobra.Command) error {
+	cfg, err := config.Decode(cfgFile)
+	var readErr *config.ReadError
+	if errors.As(err, &readErr) {
+		return err
+	}
+	problems := config.DecodeProblems(err)
+
+	// Images and fonts can still be checked when the configuration is invalid
+	if err := cfg.Init(); err != nil {
+		problems = append(problems, config.DecodeProblems(err)...)
+		problems = append(problems, render.ValidateResources(cmd.Context(), cfg)...)
+	} else {
+		problems = append(problems, render.Validate(cmd.Context(), cfg)...)
+	}
+
+	if len(problems) == 0 {
+		log.Info().Str("config", cfgFile).Msg("Configuration is valid")
+		return nil
+	}
+
+	for _, p := range problems {
+		fmt.Fprintln(cmd.OutOrStdout(), p)
+	}
+	return fmt.Errorf("%d problem(s) found in %s", len(problems), cfgFile)
+}
@@ -1,31 +1,95 @@
 package config
 
-import "math"
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
 
 const (
 	Portrait        = Orientation("portrait")
 	Landscape       = Orientation("landscape")
 	TextAlignCenter = TextAlign("center")
 	TextAlignLeft   = TextAlign("left")
+	SizingUniform   = Sizing("uniform")
+	SizingPerCell   = Sizing("per-cell")
+	FitContain      = Fit("contain")
+	FitCover        = Fit("cover")
+	FitStretch      = Fit("stretch")
 )
 
 var (
-	DefaultPageMargins = Margins{float64ptr(15), float64ptr(15), float64ptr(15), float64ptr(15)}
-	DefaultMargins     = Margins{float64ptr(2.835), float64ptr(2.835), float64ptr(2.835), float64ptr(2.835)}
-	DefaultPaddings    = Margins{float64ptr(3), float64ptr(3), float64ptr(3), float64ptr(3)}
+	DefaultPageMargins = Margins{lengthptr(15), lengthptr(15), lengthptr(15), lengthptr(15)}
+	DefaultMargins     = Margins{lengthptr(2.835), lengthptr(2.835), lengthptr(2.835), lengthptr(2.835)}
+	DefaultPaddings    = Margins{lengthptr(3), lengthptr(3), lengthptr(3), lengthptr(3)}
 	DefaultTextAlign   = TextAlignCenter
+	DefaultSizing      = SizingUniform
+	DefaultFit         = FitContain
+	DefaultFocalPoint  = FocalPoint{X: .5, Y: .5}
+	DefaultPageSize    = PageSizes["A4"]
+
+	// PageSizes are the predefined paper sizes (in points, portrait)
+	PageSizes = map[string]PageSize{
+		"A3":     {Name: "A3", W: 842, H: 1190},
+		"A4":     {Name: "A4", W: 595, H: 842},
+		"A5":     {Name: "A5", W: 420, H: 595},
+		"Letter": {Name: "Letter", W: 612, H: 792},
+		"Legal":  {Name: "Legal", W: 612, H: 1008},
+	}
 )
 
 type Orientation string
 
 type TextAlign string
 
+// Sizing is the way font sizes of picto texts are computed
+type Sizing string
+
+// Fit is the way images are scaled to their area
+type Fit string
+
+// FocalPoint is the point of an image kept visible when it is cropped,
+// X and Y being ratios of the image width and height (0.5, 0.5 being the center)
+type FocalPoint struct {
+	X float64 `mapstructure:"x" yaml:"x"`
+	Y float64 `mapstructure:"y" yaml:"y"`
+}
+
 type TextColors map[int]Color
 
 type PDF struct {
-	Page       Page        `mapstructure:"page"`
-	Text       Text        `mapstructure:"text"`
-	ImageWords []ImageWord `mapstructure:"images"`
+	Page       Page        `mapstructure:"page" yaml:"page,omitempty"`
+	Text       Text        `mapstructure:"text" yaml:"text,omitempty"`
+	ImageWords []ImageWord `mapstructure:"images" yaml:"images,omitempty"`
+	ImagesFrom ImagesFrom  `mapstructure:"images_from" yaml:"images_from,omitempty"`
+	Output     Output      `mapstructure:"output" yaml:"output,omitempty"`
+	// Categories are the colors of each category of entries, e.g. a color-coding such as the Fitzgerald key
+	Categories map[string]Category `mapstructure:"categories" yaml:"categories,omitempty"`
+}
+
+// Category are the colors of the cells of the entries belonging to a category
+type Category struct {
+	Background  *Color `mapstructure:"background" yaml:"background,omitempty"`
+	BorderColor *Color `mapstructure:"borderColor" yaml:"borderColor,omitempty"`
+}
+
+// Category returns the category named name, ignoring case as keys are lowercased when loaded
+func (p PDF) Category(name string) (Category, bool) {
+	for n, c := range p.Categories {
+		if strings.EqualFold(n, name) {
+			return c, true
+		}
+	}
+	return Category{}, false
+}
+
+// Output are the settings of images embedded in the generated documents
+type Output struct {
+	// MaxDPI is the maximum resolution of images once placed in their cell, bigger images being downsampled (0 to disable)
+	MaxDPI float64 `mapstructure:"maxDPI" yaml:"maxDPI,omitempty"`
+	// JPEGQuality is the quality of images encoded as JPEG (1 to 100)
+	JPEGQuality int `mapstructure:"jpegQuality" yaml:"jpegQuality,omitempty"`
 }
 
 func (p PDF) GetNbPictoPages() int {
@@ -33,23 +97,87 @@ func (p PDF) GetNbPictoPages() int {
 }
 
 type Page struct {
+	// TwoSidedOffsetMM is deprecated, use TwoSidedOffset instead
 	TwoSidedOffsetMM struct {
-		X float64 `mapstructure:"x"`
-		Y float64 `mapstructure:"y"`
-	} `mapstructure:"twoSidedOffsetMM"`
-	Cols        int         `mapstructure:"cols"`
-	Lines       int         `mapstructure:"lines"`
-	Orientation Orientation `mapstructure:"orientation"`
-	Margins     Margins     `mapstructure:"margins"`
-	Paddings    Margins     `mapstructure:"paddings"`
-	PageMargins Margins     `mapstructure:"page_margins"`
+		X float64 `mapstructure:"x" yaml:"x,omitempty"`
+		Y float64 `mapstructure:"y" yaml:"y,omitempty"`
+	} `mapstructure:"twoSidedOffsetMM" yaml:"twoSidedOffsetMM,omitempty"`
+	TwoSidedOffset struct {
+		X Length `mapstructure:"x" yaml:"x,omitempty"`
+		Y Length `mapstructure:"y" yaml:"y,omitempty"`
+	} `mapstructure:"twoSidedOffset" yaml:"twoSidedOffset,omitempty"`
+	Size        PageSize    `mapstructure:"size" yaml:"size,omitempty"`
+	Cols        int         `mapstructure:"cols" yaml:"cols,omitempty"`
+	Lines       int         `mapstructure:"lines" yaml:"lines,omitempty"`
+	Orientation Orientation `mapstructure:"orientation" yaml:"orientation,omitempty"`
+	Margins     Margins     `mapstructure:"margins" yaml:"margins,omitempty"`
+	Paddings    Margins     `mapstructure:"paddings" yaml:"paddings,omitempty"`
+	PageMargins Margins     `mapstructure:"page_margins" yaml:"page_margins,omitempty"`
+	Fit         Fit         `mapstructure:"fit" yaml:"fit,omitempty"`
+	FocalPoint  *FocalPoint `mapstructure:"focalPoint" yaml:"focalPoint,omitempty"`
+	BorderColor Color       `mapstructure:"borderColor" yaml:"borderColor,omitempty"`
+	Background  *Color      `mapstructure:"background" yaml:"background,omitempty"`
+}
+
+// Dimensions returns the width and height of the page in points, taking orientation into account
+func (p Page) Dimensions() (float64, float64) {
+	w, h := p.Size.W, p.Size.H
+	if (p.Orientation == Landscape && w < h) || (p.Orientation == Portrait && w > h) {
+		w, h = h, w
+	}
+	return w, h
+}
+
+// PageSize is the size of a page expressed in points
+type PageSize struct {
+	Name string
+	W, H float64
+}
+
+func (s PageSize) IsZero() bool {
+	return s.W == 0 && s.H == 0
+}
+
+// MarshalYAML marshals a page size as a preset name if it has one, as an explicit size in points otherwise
+func (s PageSize) MarshalYAML() (interface{}, error) {
+	if s.Name != "" {
+		return s.Name, nil
+	}
+	return map[string]float64{"width": s.W, "height": s.H}, nil
+}
+
+func (fp FocalPoint) validate() error {
+	if fp.X < 0 || fp.X > 1 || fp.Y < 0 || fp.Y > 1 {
+		return fmt.Errorf("x and y have to be between 0 and 1")
+	}
+	return nil
+}
+
+// Crop is a rectangle in pixels, from the upper left corner of an image
+type Crop struct {
+	X int `mapstructure:"x" yaml:"x"`
+	Y int `mapstructure:"y" yaml:"y"`
+	W int `mapstructure:"width" yaml:"width"`
+	H int `mapstructure:"height" yaml:"height"`
+}
+
+func (c Crop) validate() error {
+	if c.X < 0 || c.Y < 0 || c.W <= 0 || c.H <= 0 {
+		return fmt.Errorf("x and y have to be >= 0, width and height > 0")
+	}
+	return nil
+}
+
+// HasTransforms returns true if the image has to be cropped, rotated or flipped
+func (iw ImageWord) HasTransforms() bool {
+	return iw.Crop != nil || iw.Rotate != 0 || iw.FlipH || iw.FlipV
 }
 
 type Margins struct {
-	T *float64 `mapstructure:"top"`
-	B *float64 `mapstructure:"bottom"`
-	L *float64 `mapstructure:"left"`
-	R *float64 `mapstructure:"right"`
+	T *Length `mapstructure:"top" yaml:"top,omitempty"`
+	B *Length `mapstructure:"bottom" yaml:"bottom,omitempty"`
+	L *Length `mapstructure:"left" yaml:"left,omitempty"`
+	R *Length `mapstructure:"right" yaml:"right,omitempty"`
 }
 
 func (m *Margins) InitWithDefaults(defaults Margins) {
@@ -71,25 +199,25 @@ func (m Margins) Top() float64 {
 	if m.T == nil {
 		return 0
 	}
-	return *m.T
+	return float64(*m.T)
 }
 func (m Margins) Bottom() float64 {
 	if m.B == nil {
 		return 0
 	}
-	return *m.B
+	return float64(*m.B)
 }
 func (m Margins) Left() float64 {
 	if m.L == nil {
 		return 0
 	}
-	return *m.L
+	return float64(*m.L)
 }
 func (m Margins) Right() float64 {
 	if m.R == nil {
 		return 0
 	}
-	return *m.R
+	return float64(*m.R)
 }
 
 func (m Margins) LeftRight() float64 {
@@ -100,46 +228,153 @@ func (m Margins) TopBottom() float64 {
 }
 
 type ImageWord struct {
-	Image      string     `mapstructure:"image"`
-	Text       string     `mapstructure:"text"`
-	TextColors TextColors `mapstructure:"textColors"`
-	Def        struct {
-		Definition `mapstructure:",squash"`
-		Text       string     `mapstructure:"text"`
-		TextColors TextColors `mapstructure:"textColors"`
-	} `mapstructure:"def"`
+	// Origin is where the entry has been loaded from when not defined in the images list (e.g. words.csv:12)
+	Origin     string      `mapstructure:"-" yaml:"-"`
+	Image      string      `mapstructure:"image" yaml:"image,omitempty"`
+	Text       string      `mapstructure:"text" yaml:"text,omitempty"`
+	TextColors TextColors  `mapstructure:"textColors" yaml:"textColors,omitempty"`
+	Fit        Fit         `mapstructure:"fit" yaml:"fit,omitempty"`
+	FocalPoint *FocalPoint `mapstructure:"focalPoint" yaml:"focalPoint,omitempty"`
+	// Transforms applied to the image (after EXIF orientation) in this order: crop, rotate and flip
+	Crop   *Crop `mapstructure:"crop" yaml:"crop,omitempty"`
+	Rotate int   `mapstructure:"rotate" yaml:"rotate,omitempty"`
+	FlipH  bool  `mapstructure:"flipH" yaml:"flipH,omitempty"`
+	FlipV  bool  `mapstructure:"flipV" yaml:"flipV,omitempty"`
+	// SyllableMarks are the rune indexes of the syllables marked in Text (e.g. cho|co|lat), set when initializing
+	SyllableMarks []int `mapstructure:"-" yaml:"-"`
+	// Styles and MarkupColors are given by the markup of Text (e.g. **bold** or {red}word{/}), set when initializing
+	Styles       TextStyles `mapstructure:"-" yaml:"-"`
+	MarkupColors TextColors `mapstructure:"-" yaml:"-"`
+	// Category gives the colors of the cell unless overridden by Background and BorderColor
+	Category    string `mapstructure:"category" yaml:"category,omitempty"`
+	Background  *Color `mapstructure:"background" yaml:"background,omitempty"`
+	BorderColor *Color `mapstructure:"borderColor" yaml:"borderColor,omitempty"`
+	Def         struct {
+		Definition `mapstructure:",squash" yaml:",inline"`
+		Text       string     `mapstructure:"text" yaml:"text,omitempty"`
+		TextColors TextColors `mapstructure:"textColors" yaml:"textColors,omitempty"`
+		// SyllableMarks, Styles and MarkupColors are given by the syllable marks and the markup of Text, set when initializing
+		SyllableMarks []int      `mapstructure:"-" yaml:"-"`
+		Styles        TextStyles `mapstructure:"-" yaml:"-"`
+		MarkupColors  TextColors `mapstructure:"-" yaml:"-"`
+	} `mapstructure:"def" yaml:"def,omitempty"`
 }
 
 type Text struct {
-	Font        string     `mapstructure:"font"`
-	Ratio       float64    `mapstructure:"ratio"`
-	FontSize    float64    `mapstructure:"size"`
-	Color       Color      `mapstructure:"color"`
-	Top         bool       `mapstructure:"top"`
-	Definitions Definition `mapstructure:"definitions"`
+	Font       string     `mapstructure:"font" yaml:"font,omitempty"`
+	Ratio      float64    `mapstructure:"ratio" yaml:"ratio,omitempty"`
+	FontSize   float64    `mapstructure:"size" yaml:"size,omitempty"`
+	Sizing     Sizing     `mapstructure:"sizing" yaml:"sizing,omitempty"`
+	MinSize    float64    `mapstructure:"minSize" yaml:"minSize,omitempty"`
+	Wrap       bool       `mapstructure:"wrap" yaml:"wrap,omitempty"`
+	Color      Color      `mapstructure:"color" yaml:"color,omitempty"`
+	Top        bool       `mapstructure:"top" yaml:"top,omitempty"`
+	Background *Color     `mapstructure:"background" yaml:"background,omitempty"`
+	ColorRules ColorRules `mapstructure:"colorRules" yaml:"colorRules,omitempty"`
+	// SyllableColors are used alternately to color the syllables of picto texts and definitions
+	SyllableColors []Color `mapstructure:"syllableColors" yaml:"syllableColors,omitempty"`
+	// Language is the language of texts, used to split them into syllables
+	Language    string     `mapstructure:"language" yaml:"language,omitempty"`
+	Definitions Definition `mapstructure:"definitions" yaml:"definitions,omitempty"`
+	// FontVariants are used for the characters of picto texts styled with markup
+	FontVariants `mapstructure:",squash" yaml:",inline"`
 }
 
 type Definition struct {
-	Borders          bool      `mapstructure:"borders"`
-	Font             string    `mapstructure:"font"`
-	Size             float64   `mapstructure:"size"`
-	Color            Color     `mapstructure:"color"`
-	LineSpacingRatio float64   `mapstructure:"lineSpacingRatio"`
-	Align            TextAlign `mapstructure:"align"`
+	Borders          bool      `mapstructure:"borders" yaml:"borders,omitempty"`
+	Font             string    `mapstructure:"font" yaml:"font,omitempty"`
+	Size             float64   `mapstructure:"size" yaml:"size,omitempty"`
+	Color            Color     `mapstructure:"color" yaml:"color,omitempty"`
+	LineSpacingRatio float64   `mapstructure:"lineSpacingRatio" yaml:"lineSpacingRatio,omitempty"`
+	Align            TextAlign `mapstructure:"align" yaml:"align,omitempty"`
+	// FontVariants are used for the characters of definitions styled with markup
+	FontVariants `mapstructure:",squash" yaml:",inline"`
+}
+
+// FontVariants are the fonts of the characters styled with markup, the regular font being used for the ones not provided
+type FontVariants struct {
+	FontBold       string `mapstructure:"fontBold" yaml:"fontBold,omitempty"`
+	FontItalic     string `mapstructure:"fontItalic" yaml:"fontItalic,omitempty"`
+	FontBoldItalic string `mapstructure:"fontBoldItalic" yaml:"fontBoldItalic,omitempty"`
+}
+
+// Variant returns the font of the characters styled with style, an empty string if not provided.
+// Bold italic characters use the bold or the italic font if there is no bold italic one.
+func (v FontVariants) Variant(style TextStyle) string {
+	switch style {
+	case StyleBold:
+		return v.FontBold
+	case StyleItalic:
+		return v.FontItalic
+	case StyleBold | StyleItalic:
+		for _, f := range []string{v.FontBoldItalic, v.FontBold, v.FontItalic} {
+			if f != "" {
+				return f
+			}
+		}
+	}
+	return ""
 }
 
 type Color struct {
 	R, G, B uint8
+	// Transparency is the complement of the alpha channel so that colors are opaque unless told otherwise
+	Transparency uint8
+}
+
+// MarshalYAML marshals a color using its CSS name if it has one, as #RRGGBB (or #RRGGBBAA if transparent) otherwise
+func (c Color) MarshalYAML() (interface{}, error) {
+	// Sorting names so that colors with aliases (e.g. gray/grey) are always marshaled the same way
+	names := make([]string, 0, len(Colors))
+	for name := range Colors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c.Equals(Colors[name]) {
+			return name, nil
+		}
+	}
+	return c.Hex(), nil
+}
+
+// Hex returns the color as #RRGGBB, or as #RRGGBBAA if it is transparent
+func (c Color) Hex() string {
+	if !c.IsOpaque() {
+		return fmt.Sprintf("#%02X%02X%02X%02X", c.R, c.G, c.B, c.Alpha())
+	}
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
 }
 
 func (c Color) IsBlack() bool {
-	return c.R == 0 && c.G == 0 && c.B == 0
+	return c.R == 0 && c.G == 0 && c.B == 0 && c.IsOpaque()
+}
+
+// Alpha returns the alpha channel of the color, 255 being opaque
+func (c Color) Alpha() uint8 {
+	return 255 - c.Transparency
+}
+
+// Opacity returns the alpha channel of the color between 0 (transparent) and 1 (opaque)
+func (c Color) Opacity() float64 {
+	return float64(c.Alpha()) / 255
+}
+
+func (c Color) IsOpaque() bool {
+	return c.Transparency == 0
+}
+
+// Gray returns the shade of gray having the same luminance as c
+func (c Color) Gray() Color {
+	y := uint8(math.Round(.299*float64(c.R) + .587*float64(c.G) + .114*float64(c.B)))
+	return Color{R: y, G: y, B: y, Transparency: c.Transparency}
 }
 
 func (c Color) Equals(col Color) bool {
 	return c.R == col.R &&
 		c.G == col.G &&
-		c.B == col.B
+		c.B == col.B &&
+		c.Transparency == col.Transparency
 }
 
 func (c Color) Red() uint8 {
@@ -157,7 +392,3 @@ func (c Color) Blue() uint8 {
 func (c Color) AsUints() (uint8, uint8, uint8) {
 	return c.R, c.G, c.B
 }
-
-func float64ptr(f float64) *float64 {
-	return &f
-}
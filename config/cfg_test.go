@@ -71,3 +71,65 @@ func TestPDF_GetNbPictoPages(t *testing.T) {
 		})
 	}
 }
+
+func TestPage_Dimensions(t *testing.T) {
+	tests := []struct {
+		name  string
+		page  Page
+		wantW float64
+		wantH float64
+	}{
+		{
+			name:  "portrait A4",
+			page:  Page{Size: PageSizes["A4"], Orientation: Portrait},
+			wantW: 595,
+			wantH: 842,
+		},
+		{
+			name:  "landscape A4",
+			page:  Page{Size: PageSizes["A4"], Orientation: Landscape},
+			wantW: 842,
+			wantH: 595,
+		},
+		{
+			name:  "no orientation keeps custom size as is",
+			page:  Page{Size: PageSize{W: 300, H: 200}},
+			wantW: 300,
+			wantH: 200,
+		},
+		{
+			name:  "portrait custom size",
+			page:  Page{Size: PageSize{W: 300, H: 200}, Orientation: Portrait},
+			wantW: 200,
+			wantH: 300,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotW, gotH := tt.page.Dimensions()
+			if gotW != tt.wantW || gotH != tt.wantH {
+				t.Errorf("Dimensions() = %v, %v, want %v, %v", gotW, gotH, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestColor_Gray(t *testing.T) {
+	tests := []struct {
+		name  string
+		color Color
+		want  Color
+	}{
+		{name: "black", color: Color{}, want: Color{}},
+		{name: "white", color: Color{R: 255, G: 255, B: 255}, want: Color{R: 255, G: 255, B: 255}},
+		{name: "red", color: Color{R: 255}, want: Color{R: 76, G: 76, B: 76}},
+		{name: "tomato", color: Colors["tomato"], want: Color{R: 142, G: 142, B: 142}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.color.Gray(); got != tt.want {
+				t.Errorf("Gray() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// vowels are the letters colored by ColorRules.Vowels, other letters being consonants
+const vowels = "aeiouyàâäéèêëîïôöùûüÿæœ"
+
+// ColorRules color the letters of texts depending on their position or their kind.
+// Rules are applied in this order, each one overriding the previous ones:
+// vowels and consonants, digraphs, uppercase letters, first and last letters.
+type ColorRules struct {
+	FirstLetter *Color `mapstructure:"firstLetter" yaml:"firstLetter,omitempty"`
+	LastLetter  *Color `mapstructure:"lastLetter" yaml:"lastLetter,omitempty"`
+	Vowels      *Color `mapstructure:"vowels" yaml:"vowels,omitempty"`
+	Consonants  *Color `mapstructure:"consonants" yaml:"consonants,omitempty"`
+	Uppercase   *Color `mapstructure:"uppercase" yaml:"uppercase,omitempty"`
+	// Digraphs are groups of letters colored together (e.g. ch, ou), matched ignoring case, the longest first
+	Digraphs map[string]Color `mapstructure:"digraphs" yaml:"digraphs,omitempty"`
+}
+
+// IsZero returns true if there is no rule
+func (r ColorRules) IsZero() bool {
+	return r.FirstLetter == nil && r.LastLetter == nil && r.Vowels == nil && r.Consonants == nil &&
+		r.Uppercase == nil && len(r.Digraphs) == 0
+}
+
+// Resolve returns the color of each character of text (indexed by grapheme cluster) according to the rules,
+// explicit colors taking precedence over them
+func (r ColorRules) Resolve(text string, explicit TextColors) TextColors {
+	if r.IsZero() {
+		return explicit
+	}
+
+	// Characters are classified according to their base letter (e.g. e for e + combining acute accent)
+	runes := graphemeBases(text)
+	colors := TextColors{}
+	set := func(i int, c *Color) {
+		if c != nil {
+			colors[i] = *c
+		}
+	}
+
+	for i, c := range runes {
+		if !unicode.IsLetter(c) {
+			continue
+		}
+		if strings.ContainsRune(vowels, unicode.ToLower(c)) {
+			set(i, r.Vowels)
+		} else {
+			set(i, r.Consonants)
+		}
+	}
+
+	r.resolveDigraphs(runes, colors)
+
+	for i, c := range runes {
+		if unicode.IsUpper(c) {
+			set(i, r.Uppercase)
+		}
+	}
+
+	if first := strings.IndexFunc(string(runes), unicode.IsLetter); first >= 0 {
+		set(len([]rune(string(runes)[:first])), r.FirstLetter)
+	}
+	if last := strings.LastIndexFunc(string(runes), unicode.IsLetter); last >= 0 {
+		set(len([]rune(string(runes)[:last])), r.LastLetter)
+	}
+
+	for i, c := range explicit {
+		colors[i] = c
+	}
+	return colors
+}
+
+// resolveDigraphs colors the digraphs found in runes (the bases of grapheme clusters), scanning from the left and trying the longest digraphs first
+func (r ColorRules) resolveDigraphs(runes []rune, colors TextColors) {
+	if len(r.Digraphs) == 0 {
+		return
+	}
+
+	digraphs := make(map[string]Color, len(r.Digraphs))
+	keys := make([][]rune, 0, len(r.Digraphs))
+	for d, c := range r.Digraphs {
+		key := lowerRunes(graphemeBases(d))
+		if len(key) == 0 {
+			continue
+		}
+		digraphs[string(key)] = c
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return string(keys[i]) < string(keys[j])
+	})
+
+	lower := lowerRunes(runes)
+	for i := 0; i < len(lower); {
+		n := 1
+		for _, key := range keys {
+			if i+len(key) <= len(lower) && string(lower[i:i+len(key)]) == string(key) {
+				for j := i; j < i+len(key); j++ {
+					colors[j] = digraphs[string(key)]
+				}
+				n = len(key)
+				break
+			}
+		}
+		i += n
+	}
+}
+
+// lowerRunes lowercases each rune, keeping indexes unchanged
+func lowerRunes(runes []rune) []rune {
+	lower := make([]rune, len(runes))
+	for i, c := range runes {
+		lower[i] = unicode.ToLower(c)
+	}
+	return lower
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColorRules_Resolve(t *testing.T) {
+	red, blue, green, orange := Colors["red"], Colors["blue"], Colors["green"], Colors["orange"]
+	tests := []struct {
+		name     string
+		rules    ColorRules
+		text     string
+		explicit TextColors
+		want     TextColors
+	}{
+		{
+			name:     "no rule",
+			text:     "chat",
+			explicit: TextColors{1: red},
+			want:     TextColors{1: red},
+		},
+		{
+			name:  "first and last letters",
+			rules: ColorRules{FirstLetter: &red, LastLetter: &blue},
+			text:  "«éclair!»",
+			want:  TextColors{1: red, 6: blue},
+		},
+		{
+			name:  "vowels and consonants",
+			rules: ColorRules{Vowels: &red, Consonants: &blue},
+			text:  "Île d'or",
+			want:  TextColors{0: red, 1: blue, 2: red, 4: blue, 6: red, 7: blue},
+		},
+		{
+			name:  "uppercase overrides vowels",
+			rules: ColorRules{Vowels: &red, Uppercase: &green},
+			text:  "Ami",
+			want:  TextColors{0: green, 2: red},
+		},
+		{
+			name:  "digraphs longest first",
+			rules: ColorRules{Digraphs: map[string]Color{"ch": red, "ou": blue, "eau": green, "au": orange}},
+			text:  "Chou-beau",
+			want:  TextColors{0: red, 1: red, 2: blue, 3: blue, 6: green, 7: green, 8: green},
+		},
+		{
+			name:     "explicit colors take precedence",
+			rules:    ColorRules{FirstLetter: &red, Consonants: &blue},
+			text:     "chat",
+			explicit: TextColors{0: green},
+			want:     TextColors{0: green, 1: blue, 3: blue},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.Resolve(tt.text, tt.explicit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
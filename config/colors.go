@@ -4,154 +4,154 @@ var (
 	// Colors are all the predefined CSS colors
 	// See https://www.w3.org/wiki/CSS/Properties/color/keywords
 	// Generated from a copy/paste from this page and with the following command:
-	// cat /tmp/colors.txt | tr -d "\t" | awk '{$1=$1};1' | sed '/^$/d' | awk '{print "\"" $1 "\": {" $3  "},"}'
+	// cat /tmp/colors.txt | tr -d "\t" | awk '{$1=$1};1' | sed '/^$/d' | awk '{split($3, c, ","); print "\"" $1 "\": {R: " c[1] ", G: " c[2] ", B: " c[3] "},"}'
 	Colors = map[string]Color{
-		"aliceblue":            {240, 248, 255},
-		"antiquewhite":         {250, 235, 215},
-		"aqua":                 {0, 255, 255},
-		"aquamarine":           {127, 255, 212},
-		"azure":                {240, 255, 255},
-		"beige":                {245, 245, 220},
-		"bisque":               {255, 228, 196},
-		"black":                {0, 0, 0},
-		"blanchedalmond":       {255, 235, 205},
-		"blue":                 {0, 0, 255},
-		"blueviolet":           {138, 43, 226},
-		"brown":                {165, 42, 42},
-		"burlywood":            {222, 184, 135},
-		"cadetblue":            {95, 158, 160},
-		"chartreuse":           {127, 255, 0},
-		"chocolate":            {210, 105, 30},
-		"coral":                {255, 127, 80},
-		"cornflowerblue":       {100, 149, 237},
-		"cornsilk":             {255, 248, 220},
-		"crimson":              {220, 20, 60},
-		"cyan":                 {0, 255, 255},
-		"darkblue":             {0, 0, 139},
-		"darkcyan":             {0, 139, 139},
-		"darkgoldenrod":        {184, 134, 11},
-		"darkgray":             {169, 169, 169},
-		"darkgreen":            {0, 100, 0},
-		"darkgrey":             {169, 169, 169},
-		"darkkhaki":            {189, 183, 107},
-		"darkmagenta":          {139, 0, 139},
-		"darkolivegreen":       {85, 107, 47},
-		"darkorange":           {255, 140, 0},
-		"darkorchid":           {153, 50, 204},
-		"darkred":              {139, 0, 0},
-		"darksalmon":           {233, 150, 122},
-		"darkseagreen":         {143, 188, 143},
-		"darkslateblue":        {72, 61, 139},
-		"darkslategray":        {47, 79, 79},
-		"darkslategrey":        {47, 79, 79},
-		"darkturquoise":        {0, 206, 209},
-		"darkviolet":           {148, 0, 211},
-		"deeppink":             {255, 20, 147},
-		"deepskyblue":          {0, 191, 255},
-		"dimgray":              {105, 105, 105},
-		"dimgrey":              {105, 105, 105},
-		"dodgerblue":           {30, 144, 255},
-		"firebrick":            {178, 34, 34},
-		"floralwhite":          {255, 250, 240},
-		"forestgreen":          {34, 139, 34},
-		"fuchsia":              {255, 0, 255},
-		"gainsboro":            {220, 220, 220},
-		"ghostwhite":           {248, 248, 255},
-		"gold":                 {255, 215, 0},
-		"goldenrod":            {218, 165, 32},
-		"gray":                 {128, 128, 128},
-		"green":                {0, 128, 0},
-		"greenyellow":          {173, 255, 47},
-		"grey":                 {128, 128, 128},
-		"honeydew":             {240, 255, 240},
-		"hotpink":              {255, 105, 180},
-		"indianred":            {205, 92, 92},
-		"indigo":               {75, 0, 130},
-		"ivory":                {255, 255, 240},
-		"khaki":                {240, 230, 140},
-		"lavender":             {230, 230, 250},
-		"lavenderblush":        {255, 240, 245},
-		"lawngreen":            {124, 252, 0},
-		"lemonchiffon":         {255, 250, 205},
-		"lightblue":            {173, 216, 230},
-		"lightcoral":           {240, 128, 128},
-		"lightcyan":            {224, 255, 255},
-		"lightgoldenrodyellow": {250, 250, 210},
-		"lightgray":            {211, 211, 211},
-		"lightgreen":           {144, 238, 144},
-		"lightgrey":            {211, 211, 211},
-		"lightpink":            {255, 182, 193},
-		"lightsalmon":          {255, 160, 122},
-		"lightseagreen":        {32, 178, 170},
-		"lightskyblue":         {135, 206, 250},
-		"lightslategray":       {119, 136, 153},
-		"lightslategrey":       {119, 136, 153},
-		"lightsteelblue":       {176, 196, 222},
-		"lightyellow":          {255, 255, 224},
-		"lime":                 {0, 255, 0},
-		"limegreen":            {50, 205, 50},
-		"linen":                {250, 240, 230},
-		"magenta":              {255, 0, 255},
-		"maroon":               {128, 0, 0},
-		"mediumaquamarine":     {102, 205, 170},
-		"mediumblue":           {0, 0, 205},
-		"mediumorchid":         {186, 85, 211},
-		"mediumpurple":         {147, 112, 219},
-		"mediumseagreen":       {60, 179, 113},
-		"mediumslateblue":      {123, 104, 238},
-		"mediumspringgreen":    {0, 250, 154},
-		"mediumturquoise":      {72, 209, 204},
-		"mediumvioletred":      {199, 21, 133},
-		"midnightblue":         {25, 25, 112},
-		"mintcream":            {245, 255, 250},
-		"mistyrose":            {255, 228, 225},
-		"moccasin":             {255, 228, 181},
-		"navajowhite":          {255, 222, 173},
-		"navy":                 {0, 0, 128},
-		"oldlace":              {253, 245, 230},
-		"olive":                {128, 128, 0},
-		"olivedrab":            {107, 142, 35},
-		"orange":               {255, 165, 0},
-		"orangered":            {255, 69, 0},
-		"orchid":               {218, 112, 214},
-		"palegoldenrod":        {238, 232, 170},
-		"palegreen":            {152, 251, 152},
-		"paleturquoise":        {175, 238, 238},
-		"palevioletred":        {219, 112, 147},
-		"papayawhip":           {255, 239, 213},
-		"peachpuff":            {255, 218, 185},
-		"peru":                 {205, 133, 63},
-		"pink":                 {255, 192, 203},
-		"plum":                 {221, 160, 221},
-		"powderblue":           {176, 224, 230},
-		"purple":               {128, 0, 128},
-		"red":                  {255, 0, 0},
-		"rosybrown":            {188, 143, 143},
-		"royalblue":            {65, 105, 225},
-		"saddlebrown":          {139, 69, 19},
-		"salmon":               {250, 128, 114},
-		"sandybrown":           {244, 164, 96},
-		"seagreen":             {46, 139, 87},
-		"seashell":             {255, 245, 238},
-		"sienna":               {160, 82, 45},
-		"silver":               {192, 192, 192},
-		"skyblue":              {135, 206, 235},
-		"slateblue":            {106, 90, 205},
-		"slategray":            {112, 128, 144},
-		"slategrey":            {112, 128, 144},
-		"snow":                 {255, 250, 250},
-		"springgreen":          {0, 255, 127},
-		"steelblue":            {70, 130, 180},
-		"tan":                  {210, 180, 140},
-		"teal":                 {0, 128, 128},
-		"thistle":              {216, 191, 216},
-		"tomato":               {255, 99, 71},
-		"turquoise":            {64, 224, 208},
-		"violet":               {238, 130, 238},
-		"wheat":                {245, 222, 179},
-		"white":                {255, 255, 255},
-		"whitesmoke":           {245, 245, 245},
-		"yellow":               {255, 255, 0},
-		"yellowgreen":          {154, 205, 50},
+		"aliceblue":            {R: 240, G: 248, B: 255},
+		"antiquewhite":         {R: 250, G: 235, B: 215},
+		"aqua":                 {R: 0, G: 255, B: 255},
+		"aquamarine":           {R: 127, G: 255, B: 212},
+		"azure":                {R: 240, G: 255, B: 255},
+		"beige":                {R: 245, G: 245, B: 220},
+		"bisque":               {R: 255, G: 228, B: 196},
+		"black":                {R: 0, G: 0, B: 0},
+		"blanchedalmond":       {R: 255, G: 235, B: 205},
+		"blue":                 {R: 0, G: 0, B: 255},
+		"blueviolet":           {R: 138, G: 43, B: 226},
+		"brown":                {R: 165, G: 42, B: 42},
+		"burlywood":            {R: 222, G: 184, B: 135},
+		"cadetblue":            {R: 95, G: 158, B: 160},
+		"chartreuse":           {R: 127, G: 255, B: 0},
+		"chocolate":            {R: 210, G: 105, B: 30},
+		"coral":                {R: 255, G: 127, B: 80},
+		"cornflowerblue":       {R: 100, G: 149, B: 237},
+		"cornsilk":             {R: 255, G: 248, B: 220},
+		"crimson":              {R: 220, G: 20, B: 60},
+		"cyan":                 {R: 0, G: 255, B: 255},
+		"darkblue":             {R: 0, G: 0, B: 139},
+		"darkcyan":             {R: 0, G: 139, B: 139},
+		"darkgoldenrod":        {R: 184, G: 134, B: 11},
+		"darkgray":             {R: 169, G: 169, B: 169},
+		"darkgreen":            {R: 0, G: 100, B: 0},
+		"darkgrey":             {R: 169, G: 169, B: 169},
+		"darkkhaki":            {R: 189, G: 183, B: 107},
+		"darkmagenta":          {R: 139, G: 0, B: 139},
+		"darkolivegreen":       {R: 85, G: 107, B: 47},
+		"darkorange":           {R: 255, G: 140, B: 0},
+		"darkorchid":           {R: 153, G: 50, B: 204},
+		"darkred":              {R: 139, G: 0, B: 0},
+		"darksalmon":           {R: 233, G: 150, B: 122},
+		"darkseagreen":         {R: 143, G: 188, B: 143},
+		"darkslateblue":        {R: 72, G: 61, B: 139},
+		"darkslategray":        {R: 47, G: 79, B: 79},
+		"darkslategrey":        {R: 47, G: 79, B: 79},
+		"darkturquoise":        {R: 0, G: 206, B: 209},
+		"darkviolet":           {R: 148, G: 0, B: 211},
+		"deeppink":             {R: 255, G: 20, B: 147},
+		"deepskyblue":          {R: 0, G: 191, B: 255},
+		"dimgray":              {R: 105, G: 105, B: 105},
+		"dimgrey":              {R: 105, G: 105, B: 105},
+		"dodgerblue":           {R: 30, G: 144, B: 255},
+		"firebrick":            {R: 178, G: 34, B: 34},
+		"floralwhite":          {R: 255, G: 250, B: 240},
+		"forestgreen":          {R: 34, G: 139, B: 34},
+		"fuchsia":              {R: 255, G: 0, B: 255},
+		"gainsboro":            {R: 220, G: 220, B: 220},
+		"ghostwhite":           {R: 248, G: 248, B: 255},
+		"gold":                 {R: 255, G: 215, B: 0},
+		"goldenrod":            {R: 218, G: 165, B: 32},
+		"gray":                 {R: 128, G: 128, B: 128},
+		"green":                {R: 0, G: 128, B: 0},
+		"greenyellow":          {R: 173, G: 255, B: 47},
+		"grey":                 {R: 128, G: 128, B: 128},
+		"honeydew":             {R: 240, G: 255, B: 240},
+		"hotpink":              {R: 255, G: 105, B: 180},
+		"indianred":            {R: 205, G: 92, B: 92},
+		"indigo":               {R: 75, G: 0, B: 130},
+		"ivory":                {R: 255, G: 255, B: 240},
+		"khaki":                {R: 240, G: 230, B: 140},
+		"lavender":             {R: 230, G: 230, B: 250},
+		"lavenderblush":        {R: 255, G: 240, B: 245},
+		"lawngreen":            {R: 124, G: 252, B: 0},
+		"lemonchiffon":         {R: 255, G: 250, B: 205},
+		"lightblue":            {R: 173, G: 216, B: 230},
+		"lightcoral":           {R: 240, G: 128, B: 128},
+		"lightcyan":            {R: 224, G: 255, B: 255},
+		"lightgoldenrodyellow": {R: 250, G: 250, B: 210},
+		"lightgray":            {R: 211, G: 211, B: 211},
+		"lightgreen":           {R: 144, G: 238, B: 144},
+		"lightgrey":            {R: 211, G: 211, B: 211},
+		"lightpink":            {R: 255, G: 182, B: 193},
+		"lightsalmon":          {R: 255, G: 160, B: 122},
+		"lightseagreen":        {R: 32, G: 178, B: 170},
+		"lightskyblue":         {R: 135, G: 206, B: 250},
+		"lightslategray":       {R: 119, G: 136, B: 153},
+		"lightslategrey":       {R: 119, G: 136, B: 153},
+		"lightsteelblue":       {R: 176, G: 196, B: 222},
+		"lightyellow":          {R: 255, G: 255, B: 224},
+		"lime":                 {R: 0, G: 255, B: 0},
+		"limegreen":            {R: 50, G: 205, B: 50},
+		"linen":                {R: 250, G: 240, B: 230},
+		"magenta":              {R: 255, G: 0, B: 255},
+		"maroon":               {R: 128, G: 0, B: 0},
+		"mediumaquamarine":     {R: 102, G: 205, B: 170},
+		"mediumblue":           {R: 0, G: 0, B: 205},
+		"mediumorchid":         {R: 186, G: 85, B: 211},
+		"mediumpurple":         {R: 147, G: 112, B: 219},
+		"mediumseagreen":       {R: 60, G: 179, B: 113},
+		"mediumslateblue":      {R: 123, G: 104, B: 238},
+		"mediumspringgreen":    {R: 0, G: 250, B: 154},
+		"mediumturquoise":      {R: 72, G: 209, B: 204},
+		"mediumvioletred":      {R: 199, G: 21, B: 133},
+		"midnightblue":         {R: 25, G: 25, B: 112},
+		"mintcream":            {R: 245, G: 255, B: 250},
+		"mistyrose":            {R: 255, G: 228, B: 225},
+		"moccasin":             {R: 255, G: 228, B: 181},
+		"navajowhite":          {R: 255, G: 222, B: 173},
+		"navy":                 {R: 0, G: 0, B: 128},
+		"oldlace":              {R: 253, G: 245, B: 230},
+		"olive":                {R: 128, G: 128, B: 0},
+		"olivedrab":            {R: 107, G: 142, B: 35},
+		"orange":               {R: 255, G: 165, B: 0},
+		"orangered":            {R: 255, G: 69, B: 0},
+		"orchid":               {R: 218, G: 112, B: 214},
+		"palegoldenrod":        {R: 238, G: 232, B: 170},
+		"palegreen":            {R: 152, G: 251, B: 152},
+		"paleturquoise":        {R: 175, G: 238, B: 238},
+		"palevioletred":        {R: 219, G: 112, B: 147},
+		"papayawhip":           {R: 255, G: 239, B: 213},
+		"peachpuff":            {R: 255, G: 218, B: 185},
+		"peru":                 {R: 205, G: 133, B: 63},
+		"pink":                 {R: 255, G: 192, B: 203},
+		"plum":                 {R: 221, G: 160, B: 221},
+		"powderblue":           {R: 176, G: 224, B: 230},
+		"purple":               {R: 128, G: 0, B: 128},
+		"red":                  {R: 255, G: 0, B: 0},
+		"rosybrown":            {R: 188, G: 143, B: 143},
+		"royalblue":            {R: 65, G: 105, B: 225},
+		"saddlebrown":          {R: 139, G: 69, B: 19},
+		"salmon":               {R: 250, G: 128, B: 114},
+		"sandybrown":           {R: 244, G: 164, B: 96},
+		"seagreen":             {R: 46, G: 139, B: 87},
+		"seashell":             {R: 255, G: 245, B: 238},
+		"sienna":               {R: 160, G: 82, B: 45},
+		"silver":               {R: 192, G: 192, B: 192},
+		"skyblue":              {R: 135, G: 206, B: 235},
+		"slateblue":            {R: 106, G: 90, B: 205},
+		"slategray":            {R: 112, G: 128, B: 144},
+		"slategrey":            {R: 112, G: 128, B: 144},
+		"snow":                 {R: 255, G: 250, B: 250},
+		"springgreen":          {R: 0, G: 255, B: 127},
+		"steelblue":            {R: 70, G: 130, B: 180},
+		"tan":                  {R: 210, G: 180, B: 140},
+		"teal":                 {R: 0, G: 128, B: 128},
+		"thistle":              {R: 216, G: 191, B: 216},
+		"tomato":               {R: 255, G: 99, B: 71},
+		"turquoise":            {R: 64, G: 224, B: 208},
+		"violet":               {R: 238, G: 130, B: 238},
+		"wheat":                {R: 245, G: 222, B: 179},
+		"white":                {R: 255, G: 255, B: 255},
+		"whitesmoke":           {R: 245, G: 245, B: 245},
+		"yellow":               {R: 255, G: 255, B: 0},
+		"yellowgreen":          {R: 154, G: 205, B: 50},
 	}
 )
@@ -0,0 +1,257 @@
+package config
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ImagesFrom describes a CSV or TSV file to load images from
+type ImagesFrom struct {
+	File string `mapstructure:"file" yaml:"file,omitempty"`
+	// Separator is inferred from the file extension if not provided (tab for .tsv and .tab, comma otherwise)
+	Separator string `mapstructure:"separator" yaml:"separator,omitempty"`
+	// NoHeader has to be set when the first line of the file is not a header,
+	// columns have to be referenced by their index (starting at 0) in that case
+	NoHeader bool              `mapstructure:"noHeader" yaml:"noHeader,omitempty"`
+	Columns  ImagesFromColumns `mapstructure:"columns" yaml:"columns,omitempty"`
+}
+
+// ImagesFromColumns maps each ImageWord field to a column name or index.
+// Text colors columns use the format index:color separated by semicolons (e.g. "0:red;3:blue").
+type ImagesFromColumns struct {
+	Image         string `mapstructure:"image" yaml:"image,omitempty"`
+	Text          string `mapstructure:"text" yaml:"text,omitempty"`
+	TextColors    string `mapstructure:"textColors" yaml:"textColors,omitempty"`
+	DefText       string `mapstructure:"defText" yaml:"defText,omitempty"`
+	DefFont       string `mapstructure:"defFont" yaml:"defFont,omitempty"`
+	DefSize       string `mapstructure:"defSize" yaml:"defSize,omitempty"`
+	DefColor      string `mapstructure:"defColor" yaml:"defColor,omitempty"`
+	DefTextColors string `mapstructure:"defTextColors" yaml:"defTextColors,omitempty"`
+	Category      string `mapstructure:"category" yaml:"category,omitempty"`
+	Background    string `mapstructure:"background" yaml:"background,omitempty"`
+}
+
+// DefaultImagesFromColumns are the columns used when not provided, their index being used if the file has no header
+var DefaultImagesFromColumns = ImagesFromColumns{
+	Image:         "image",
+	Text:          "text",
+	TextColors:    "textColors",
+	DefText:       "def.text",
+	DefFont:       "def.font",
+	DefSize:       "def.size",
+	DefColor:      "def.color",
+	DefTextColors: "def.textColors",
+	Category:      "category",
+	Background:    "background",
+}
+
+// csvColumn is a column of a CSV file and the setter storing its value into an ImageWord
+type csvColumn struct {
+	ref          string // column name or index from the configuration
+	defaultName  string // name used when ref is not provided and the file has a header
+	defaultIndex int    // index used when ref is not provided and the file has no header
+	set          func(iw *ImageWord, value string) error
+}
+
+// ImageWordsFromCSV loads an ImageWord for each line of a CSV or TSV file
+func ImageWordsFromCSV(from ImagesFrom) ([]ImageWord, error) {
+	file, err := os.Open(from.File)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %w", from.File, err)
+	}
+	defer file.Close()
+
+	return readImageWordsCSV(file, from)
+}
+
+func readImageWordsCSV(r io.Reader, from ImagesFrom) ([]ImageWord, error) {
+	comma := ','
+	switch {
+	case from.Separator != "":
+		sep := []rune(strings.ReplaceAll(from.Separator, `\t`, "\t"))
+		if len(sep) != 1 {
+			return nil, fmt.Errorf("%s: separator has to be a single character", from.File)
+		}
+		comma = sep[0]
+	case strings.EqualFold(filepath.Ext(from.File), ".tsv"), strings.EqualFold(filepath.Ext(from.File), ".tab"):
+		comma = '\t'
+	}
+
+	// Excel starts UTF-8 files with a byte order mark, which would otherwise be part of the first column name
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(len(utf8BOM)); string(bom) == utf8BOM {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.Comma = comma
+	if comma == '\t' {
+		reader.LazyQuotes = true
+	} else {
+		// Trimming would merge consecutive empty fields with a whitespace separator
+		reader.TrimLeadingSpace = true
+	}
+
+	columns := csvColumns(from.Columns)
+
+	var header []string
+	if !from.NoHeader {
+		h, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return []ImageWord{}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", from.File, err)
+		}
+		header = h
+	}
+
+	// Resolving each column to its index in the file
+	indexes := make([]int, len(columns))
+	for i, col := range columns {
+		idx, err := csvColumnIndex(col, header)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", from.File, err)
+		}
+		indexes[i] = idx
+	}
+
+	iws := make([]ImageWord, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", from.File, err)
+		}
+		if isEmptyRecord(record) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		iw := ImageWord{Origin: fmt.Sprintf("%s:%d", from.File, line)}
+		for i, col := range columns {
+			idx := indexes[i]
+			if idx < 0 || idx >= len(record) || strings.TrimSpace(record[idx]) == "" {
+				continue
+			}
+			if err := col.set(&iw, strings.TrimSpace(record[idx])); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", from.File, line, err)
+			}
+		}
+		iws = append(iws, iw)
+	}
+
+	return iws, nil
+}
+
+func csvColumns(c ImagesFromColumns) []csvColumn {
+	d := DefaultImagesFromColumns
+	return []csvColumn{
+		{c.Image, d.Image, 0, func(iw *ImageWord, v string) error { iw.Image = v; return nil }},
+		{c.Text, d.Text, 1, func(iw *ImageWord, v string) error { iw.Text = v; return nil }},
+		{c.TextColors, d.TextColors, 2, func(iw *ImageWord, v string) (err error) {
+			iw.TextColors, err = ParseTextColors(v)
+			return err
+		}},
+		{c.DefText, d.DefText, 3, func(iw *ImageWord, v string) error { iw.Def.Text = v; return nil }},
+		{c.DefFont, d.DefFont, 4, func(iw *ImageWord, v string) error { iw.Def.Font = v; return nil }},
+		{c.DefSize, d.DefSize, 5, func(iw *ImageWord, v string) error {
+			size, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("invalid definition size %s", v)
+			}
+			iw.Def.Size = size
+			return nil
+		}},
+		{c.DefColor, d.DefColor, 6, func(iw *ImageWord, v string) (err error) {
+			iw.Def.Color, err = ParseColor(v)
+			return err
+		}},
+		{c.DefTextColors, d.DefTextColors, 7, func(iw *ImageWord, v string) (err error) {
+			iw.Def.TextColors, err = ParseTextColors(v)
+			return err
+		}},
+		{c.Category, d.Category, 8, func(iw *ImageWord, v string) error { iw.Category = v; return nil }},
+		{c.Background, d.Background, 9, func(iw *ImageWord, v string) error {
+			color, err := ParseColor(v)
+			if err != nil {
+				return err
+			}
+			iw.Background = &color
+			return nil
+		}},
+	}
+}
+
+// csvColumnIndex returns the index of a column, -1 if the column is not in the file and was not explicitly asked
+func csvColumnIndex(col csvColumn, header []string) (int, error) {
+	if header == nil {
+		if col.ref == "" {
+			return col.defaultIndex, nil
+		}
+		idx, err := strconv.Atoi(col.ref)
+		if err != nil || idx < 0 {
+			return 0, fmt.Errorf("column %s has to be an index when the file has no header", col.ref)
+		}
+		return idx, nil
+	}
+
+	explicit := col.ref != ""
+	if !explicit {
+		col.ref = col.defaultName
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), col.ref) {
+			return i, nil
+		}
+	}
+	if idx, err := strconv.Atoi(col.ref); err == nil && idx >= 0 {
+		return idx, nil
+	}
+	if explicit {
+		return 0, fmt.Errorf("column %s not found in header", col.ref)
+	}
+	return -1, nil
+}
+
+const utf8BOM = "\ufeff"
+
+func isEmptyRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseTextColors parses text colors given as index:color separated by semicolons (e.g. "0:red;3:blue"),
+// index being a single index or a range of indexes (e.g. "0-3:red")
+func ParseTextColors(raw string) (TextColors, error) {
+	colors := map[string]Color{}
+	for _, spec := range strings.Split(raw, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		parts := strings.SplitN(spec, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid text color %s (format: index:color)", spec)
+		}
+		color, err := ParseColor(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+		colors[strings.TrimSpace(parts[0])] = color
+	}
+	return newTextColors(colors)
+}
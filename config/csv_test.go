@@ -0,0 +1,121 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadImageWordsCSV(t *testing.T) {
+	t.Run("header with default columns", func(t *testing.T) {
+		data := "image,text,def.text,def.size,def.color,textColors\n" +
+			"chat.png,Chat,Le chat miaule,12,red,0:blue;2:green\n" +
+			"\n" +
+			"chien.png,Chien,,,,\n"
+		iws, err := readImageWordsCSV(strings.NewReader(data), ImagesFrom{File: "words.csv"})
+		if err != nil {
+			t.Fatalf("readImageWordsCSV() error = %v", err)
+		}
+		if len(iws) != 2 {
+			t.Fatalf("readImageWordsCSV() got %d images, want 2", len(iws))
+		}
+		chat := iws[0]
+		if chat.Image != "chat.png" || chat.Text != "Chat" || chat.Def.Text != "Le chat miaule" || chat.Def.Size != 12 {
+			t.Errorf("readImageWordsCSV() got %+v", chat)
+		}
+		if !chat.Def.Color.Equals(Colors["red"]) || !chat.TextColors[0].Equals(Colors["blue"]) || !chat.TextColors[2].Equals(Colors["green"]) {
+			t.Errorf("readImageWordsCSV() wrong colors %+v", chat)
+		}
+		if chat.Origin != "words.csv:2" || iws[1].Origin != "words.csv:4" {
+			t.Errorf("readImageWordsCSV() wrong origins %s, %s", chat.Origin, iws[1].Origin)
+		}
+	})
+
+	t.Run("multi-line cell", func(t *testing.T) {
+		data := "image,text,def.text\n" +
+			"chat.png,Chat,\"Le chat\n\nmiaule\"\n" +
+			"\n" +
+			"chien.png,Chien,\n"
+		iws, err := readImageWordsCSV(strings.NewReader(data), ImagesFrom{File: "words.csv"})
+		if err != nil {
+			t.Fatalf("readImageWordsCSV() error = %v", err)
+		}
+		if len(iws) != 2 || iws[0].Def.Text != "Le chat\n\nmiaule" {
+			t.Fatalf("readImageWordsCSV() got %+v", iws)
+		}
+		if iws[0].Origin != "words.csv:2" || iws[1].Origin != "words.csv:6" {
+			t.Errorf("readImageWordsCSV() wrong origins %s, %s", iws[0].Origin, iws[1].Origin)
+		}
+	})
+
+	t.Run("byte order mark", func(t *testing.T) {
+		data := "\ufeffimage,text\nchat.png,Chat\n"
+		iws, err := readImageWordsCSV(strings.NewReader(data), ImagesFrom{File: "words.csv"})
+		if err != nil {
+			t.Fatalf("readImageWordsCSV() error = %v", err)
+		}
+		if len(iws) != 1 || iws[0].Image != "chat.png" || iws[0].Text != "Chat" {
+			t.Errorf("readImageWordsCSV() got %+v", iws)
+		}
+	})
+
+	t.Run("tsv with mapped columns", func(t *testing.T) {
+		data := "mot\tfichier\tdefinition\n" +
+			"Pomme\tpomme.jpg\tUn fruit, rouge ou vert\n"
+		iws, err := readImageWordsCSV(strings.NewReader(data), ImagesFrom{
+			File:    "words.tsv",
+			Columns: ImagesFromColumns{Image: "fichier", Text: "mot", DefText: "definition"},
+		})
+		if err != nil {
+			t.Fatalf("readImageWordsCSV() error = %v", err)
+		}
+		if len(iws) != 1 || iws[0].Image != "pomme.jpg" || iws[0].Text != "Pomme" || iws[0].Def.Text != "Un fruit, rouge ou vert" {
+			t.Errorf("readImageWordsCSV() got %+v", iws)
+		}
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		data := "Pomme;pomme.jpg\n"
+		iws, err := readImageWordsCSV(strings.NewReader(data), ImagesFrom{
+			File:      "words.txt",
+			Separator: ";",
+			NoHeader:  true,
+			Columns:   ImagesFromColumns{Image: "1", Text: "0"},
+		})
+		if err != nil {
+			t.Fatalf("readImageWordsCSV() error = %v", err)
+		}
+		if len(iws) != 1 || iws[0].Image != "pomme.jpg" || iws[0].Text != "Pomme" {
+			t.Errorf("readImageWordsCSV() got %+v", iws)
+		}
+	})
+
+	t.Run("category and background", func(t *testing.T) {
+		data := "image,text,category,background\n" +
+			"manger.png,Manger,verb,\n" +
+			"pomme.png,Pomme,,#ffa500\n"
+		iws, err := readImageWordsCSV(strings.NewReader(data), ImagesFrom{File: "words.csv"})
+		if err != nil {
+			t.Fatalf("readImageWordsCSV() error = %v", err)
+		}
+		if len(iws) != 2 || iws[0].Category != "verb" || iws[0].Background != nil || !iws[1].Background.Equals(Colors["orange"]) {
+			t.Errorf("readImageWordsCSV() got %+v", iws)
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		_, err := readImageWordsCSV(strings.NewReader("image,text\n"), ImagesFrom{
+			File:    "words.csv",
+			Columns: ImagesFromColumns{Text: "word"},
+		})
+		if err == nil {
+			t.Errorf("readImageWordsCSV() expected an error for an unknown column")
+		}
+	})
+
+	t.Run("invalid color", func(t *testing.T) {
+		_, err := readImageWordsCSV(strings.NewReader("image,def.color\na.png,notacolor\n"), ImagesFrom{File: "words.csv"})
+		if err == nil || !strings.Contains(err.Error(), "words.csv:2") {
+			t.Errorf("readImageWordsCSV() expected an error with line number, got %v", err)
+		}
+	})
+}
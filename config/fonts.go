@@ -2,13 +2,17 @@ package config
 
 import (
 	"bytes"
+	_ "embed"
 	"encoding/base64"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 )
 
 const DefaultFont = "rockwell"
 
+// Font is a bundled font encoded in base64
 type Font string
 
 var (
@@ -18,16 +22,54 @@ var (
 	}
 )
 
-func LoadFont(name string) (io.Reader, error) {
+var (
+	//go:embed Combo-Regular.ttf
+	comboRegular []byte
+	//go:embed OleoScript-Regular.ttf
+	oleoScriptRegular []byte
+
+	// fontFiles are the bundled fonts shipped as TTF files
+	fontFiles = map[string][]byte{
+		"combo":      comboRegular,
+		"oleoscript": oleoScriptRegular,
+	}
+)
+
+// FontNames returns the names of the bundled fonts, sorted
+func FontNames() []string {
+	names := make([]string, 0, len(Fonts)+len(fontFiles))
+	for name := range Fonts {
+		names = append(names, name)
+	}
+	for name := range fontFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BundledFont returns the TTF data of the bundled font called name (case insensitive)
+func BundledFont(name string) ([]byte, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if data, ok := fontFiles[name]; ok {
+		return data, true
+	}
 	font, ok := Fonts[name]
 	if !ok {
-		return nil, fmt.Errorf("unable to find font %s", name)
+		return nil, false
 	}
-
 	data, err := base64.StdEncoding.DecodeString(string(font))
 	if err != nil {
-		return nil, err
+		return nil, false
 	}
+	return data, true
+}
 
+// LoadFont returns a reader on the TTF data of the bundled font called name
+func LoadFont(name string) (io.Reader, error) {
+	data, ok := BundledFont(name)
+	if !ok {
+		return nil, fmt.Errorf("unable to find font %s", name)
+	}
 	return bytes.NewReader(data), nil
 }
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestFontNames(t *testing.T) {
+	want := []string{"combo", "loma", "oleoscript", "rockwell"}
+	if got := FontNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FontNames() = %v, want %v", got, want)
+	}
+}
+
+func TestBundledFont(t *testing.T) {
+	for _, name := range append(FontNames(), "Loma", " OleoScript ") {
+		data, ok := BundledFont(name)
+		// TTF files start with the version 1.0 of the sfnt format
+		if !ok || len(data) < 4 || string(data[:4]) != "\x00\x01\x00\x00" {
+			t.Errorf("BundledFont(%q) is not a TTF font", name)
+		}
+	}
+	if _, ok := BundledFont("config/Combo-Regular.ttf"); ok {
+		t.Errorf("BundledFont() expected no font for a path")
+	}
+}
+
+func TestLoadFont(t *testing.T) {
+	r, err := LoadFont(DefaultFont)
+	if err != nil {
+		t.Fatalf("LoadFont() error = %v", err)
+	}
+	if data, err := ioutil.ReadAll(r); err != nil || len(data) == 0 {
+		t.Errorf("LoadFont() read %d bytes, error = %v", len(data), err)
+	}
+	if _, err := LoadFont("unknown"); err == nil {
+		t.Errorf("LoadFont() expected an error for an unknown font")
+	}
+}
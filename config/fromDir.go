@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ImageExtensions are the file extensions considered as images when scanning a directory
+var ImageExtensions = []string{".jpg", ".jpeg", ".png", ".svg", ".gif", ".bmp", ".tif", ".tiff", ".webp"}
+
+// numericPrefixRegexp matches numeric prefixes used to sort files (e.g. "01_", "2-", "003 ")
+var numericPrefixRegexp = regexp.MustCompile(`^\d+[\s_.-]*`)
+
+// ImageWordsFromDir scans dir recursively and returns an ImageWord for each image found.
+// If glob is not empty, only files whose name matches it are kept.
+// The text is derived from the file name and a sidecar file with the same name and a .txt extension
+// is used as the definition text if it exists.
+func ImageWordsFromDir(dir string, glob string) ([]ImageWord, error) {
+	if glob != "" {
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %s: %w", glob, err)
+		}
+	}
+
+	iws := make([]ImageWord, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isImageFile(path) {
+			return nil
+		}
+		if glob != "" {
+			if ok, _ := filepath.Match(glob, d.Name()); !ok {
+				return nil
+			}
+		}
+
+		iw := ImageWord{
+			Image: path,
+			Text:  TextFromFileName(d.Name()),
+		}
+
+		sidecar := strings.TrimSuffix(path, filepath.Ext(path)) + ".txt"
+		data, err := ioutil.ReadFile(sidecar)
+		if err == nil {
+			iw.Def.Text = strings.TrimSpace(string(data))
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("unable to read %s: %w", sidecar, err)
+		}
+
+		iws = append(iws, iw)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to scan directory %s: %w", dir, err)
+	}
+
+	return iws, nil
+}
+
+// TextFromFileName derives a picto text from a file name, e.g. "01_pomme_rouge.jpg" gives "pomme rouge"
+func TextFromFileName(name string) string {
+	base := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	text := numericPrefixRegexp.ReplaceAllString(base, "")
+	if text == "" { // file name is only made of digits
+		text = base
+	}
+	text = strings.ReplaceAll(text, "_", " ")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func isImageFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range ImageExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTextFromFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "simple", file: "pomme.jpg", want: "pomme"},
+		{name: "underscores", file: "pomme_rouge.png", want: "pomme rouge"},
+		{name: "numeric prefix", file: "01_pomme_rouge.jpeg", want: "pomme rouge"},
+		{name: "numeric prefix with dash", file: "12-arc-en-ciel.png", want: "arc-en-ciel"},
+		{name: "only digits", file: "2024.png", want: "2024"},
+		{name: "with directory", file: "some/dir/003 chat.jpg", want: "chat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TextFromFileName(tt.file); got != tt.want {
+				t.Errorf("TextFromFileName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageWordsFromDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"01_chat.png":          "",
+		"01_chat.txt":          "  Le chat miaule.\n",
+		"sub/02_chien.jpg":     "",
+		"sub/notes.md":         "",
+		"sub/deeper/lapin.PNG": "",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	iws, err := ImageWordsFromDir(dir, "")
+	if err != nil {
+		t.Fatalf("ImageWordsFromDir() error = %v", err)
+	}
+	want := []struct{ image, text, def string }{
+		{"01_chat.png", "chat", "Le chat miaule."},
+		{"sub/02_chien.jpg", "chien", ""},
+		{"sub/deeper/lapin.PNG", "lapin", ""},
+	}
+	if len(iws) != len(want) {
+		t.Fatalf("ImageWordsFromDir() got %d images, want %d", len(iws), len(want))
+	}
+	for i, w := range want {
+		if iws[i].Image != filepath.Join(dir, w.image) || iws[i].Text != w.text || iws[i].Def.Text != w.def {
+			t.Errorf("ImageWordsFromDir()[%d] = %+v, want %+v", i, iws[i], w)
+		}
+	}
+
+	iws, err = ImageWordsFromDir(dir, "*.jpg")
+	if err != nil {
+		t.Fatalf("ImageWordsFromDir() error = %v", err)
+	}
+	if len(iws) != 1 || iws[0].Text != "chien" {
+		t.Errorf("ImageWordsFromDir() with glob = %+v", iws)
+	}
+}
@@ -0,0 +1,39 @@
+package config
+
+import "github.com/rivo/uniseg"
+
+// Graphemes splits text into user-perceived characters (grapheme clusters), e.g. a letter and its combining accents or an emoji.
+// TextColors are indexed by grapheme cluster.
+func Graphemes(text string) []string {
+	clusters := make([]string, 0, len(text))
+	g := uniseg.NewGraphemes(text)
+	for g.Next() {
+		clusters = append(clusters, g.Str())
+	}
+	return clusters
+}
+
+// graphemeBases returns the first rune of each grapheme cluster of text, e.g. to tell whether it is a letter
+func graphemeBases(text string) []rune {
+	clusters := Graphemes(text)
+	bases := make([]rune, len(clusters))
+	for i, c := range clusters {
+		bases[i] = []rune(c)[0]
+	}
+	return bases
+}
+
+// graphemeIndexes returns the index of the grapheme cluster each rune of text belongs to,
+// followed by the number of clusters so that the end of a range of runes can be converted too
+func graphemeIndexes(text string) []int {
+	indexes := make([]int, 0, len(text)+1)
+	n := 0
+	g := uniseg.NewGraphemes(text)
+	for g.Next() {
+		for range g.Runes() {
+			indexes = append(indexes, n)
+		}
+		n++
+	}
+	return append(indexes, n)
+}
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/nmaupu/gopicto/syllable"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"path/filepath"
+)
+
+const (
+	DefaultLineSpacingRatio   = .3
+	DefaultImageWordTextRatio = 1.0 / 5
+	DefaultTwoSidedOffsetMMx  = -3
+	DefaultTwoSidedOffsetMMy  = 0
+	DefaultJPEGQuality        = 90
+)
+
+// DecodeHook returns the hooks used to decode a configuration file into a PDF
+func DecodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToSliceHookFunc(","),
+		MapstructureStringToLength(),
+		MapstructureStringToFloat64Expr(),
+		MapstructureStringToColor(),
+		MapstructureStringToOrientation(),
+		MapstructureStringToTextAlign(),
+		MapstructureStringToSizing(),
+		MapstructureStringToFit(),
+		MapstructureToPageSize(),
+		MapstructureStringToImagesFrom(),
+		MapstructureToTextColors(),
+	)
+}
+
+// Load reads the given configuration file, decodes it and initializes it with default values
+func Load(file string) (PDF, error) {
+	cfg, err := Decode(file)
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := cfg.Init(); err != nil {
+		return cfg, fmt.Errorf("invalid configuration file %s: %w", file, err)
+	}
+
+	return cfg, nil
+}
+
+// Decode reads and decodes the given configuration file without initializing it.
+// When some fields cannot be decoded, the returned configuration is partially filled
+// and the error can be inspected with DecodeProblems.
+func Decode(file string) (PDF, error) {
+	cfg := PDF{}
+
+	v := viper.New()
+	v.AutomaticEnv()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return cfg, &ReadError{File: file, Err: err}
+	}
+	var unmarshalErr error
+	if err := v.Unmarshal(&cfg, viper.DecodeHook(DecodeHook())); err != nil {
+		unmarshalErr = fmt.Errorf("unable to unmarshal configuration file %s: %w", file, err)
+	}
+
+	// Images are loaded even if other fields could not be decoded, so that all the problems are reported
+	if cfg.ImagesFrom.File != "" {
+		// The file and its images are relative to the configuration file
+		dir := filepath.Dir(file)
+		cfg.ImagesFrom.File = resolvePath(dir, cfg.ImagesFrom.File)
+		iws, err := ImageWordsFromCSV(cfg.ImagesFrom)
+		if err != nil && unmarshalErr != nil {
+			return cfg, append(Problems(DecodeProblems(unmarshalErr)), DecodeProblems(err)...)
+		}
+		if err != nil {
+			return cfg, err
+		}
+		for k := range iws {
+			if iws[k].Image != "" {
+				iws[k].Image = resolvePath(dir, iws[k].Image)
+			}
+		}
+		cfg.ImageWords = append(cfg.ImageWords, iws...)
+	}
+
+	return cfg, unmarshalErr
+}
+
+// resolvePath returns path relative to dir if it is not absolute
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// ReadError is returned when a configuration file cannot be read at all
+type ReadError struct {
+	File string
+	Err  error
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("unable to read configuration file %s: %s", e.File, e.Err)
+}
+
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// Init validates the configuration and sets default values for everything not provided.
+// All the problems found are returned as Problems, everything valid being initialized anyway.
+func (p *PDF) Init() error {
+	problems := make(Problems, 0)
+	addProblem := func(path string, format string, a ...interface{}) {
+		problems = append(problems, Problem{Path: path, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if p.Page.Lines <= 0 || p.Page.Cols <= 0 {
+		addProblem("page", "cols and lines have to be > 0")
+	}
+
+	if p.Page.Size.IsZero() {
+		p.Page.Size = DefaultPageSize
+	}
+
+	p.Page.PageMargins.InitWithDefaults(DefaultPageMargins)
+	p.Page.Margins.InitWithDefaults(DefaultMargins)
+	p.Page.Paddings.InitWithDefaults(DefaultPaddings)
+
+	// twoSidedOffsetMM is deprecated but still honoured when twoSidedOffset is not set
+	if p.Page.TwoSidedOffsetMM.X != 0 || p.Page.TwoSidedOffsetMM.Y != 0 {
+		log.Warn().Msg("Config page.twoSidedOffsetMM is deprecated, use page.twoSidedOffset instead (e.g. x: -3mm)")
+	}
+	if p.Page.TwoSidedOffset.X == 0 && p.Page.TwoSidedOffsetMM.X != 0 {
+		p.Page.TwoSidedOffset.X = Length(UnitMM.ToPoints(p.Page.TwoSidedOffsetMM.X))
+	}
+	if p.Page.TwoSidedOffset.Y == 0 && p.Page.TwoSidedOffsetMM.Y != 0 {
+		p.Page.TwoSidedOffset.Y = Length(UnitMM.ToPoints(p.Page.TwoSidedOffsetMM.Y))
+	}
+
+	if p.Page.TwoSidedOffset.X == 0 {
+		p.Page.TwoSidedOffset.X = Length(UnitMM.ToPoints(DefaultTwoSidedOffsetMMx))
+	}
+
+	if p.Page.TwoSidedOffset.Y == 0 {
+		p.Page.TwoSidedOffset.Y = Length(UnitMM.ToPoints(DefaultTwoSidedOffsetMMy))
+	}
+
+	if p.Page.Fit == "" {
+		p.Page.Fit = DefaultFit
+	}
+	if p.Page.FocalPoint == nil {
+		fp := DefaultFocalPoint
+		p.Page.FocalPoint = &fp
+	}
+	if err := p.Page.FocalPoint.validate(); err != nil {
+		addProblem("page.focalPoint", "%s", err)
+	}
+
+	if p.Output.MaxDPI < 0 {
+		addProblem("output.maxDPI", "has to be >= 0")
+	}
+	if p.Output.JPEGQuality == 0 {
+		p.Output.JPEGQuality = DefaultJPEGQuality
+	}
+	if p.Output.JPEGQuality < 1 || p.Output.JPEGQuality > 100 {
+		addProblem("output.jpegQuality", "has to be between 1 and 100")
+	}
+
+	if p.Text.Ratio == 0.0 {
+		p.Text.Ratio = DefaultImageWordTextRatio
+	}
+
+	if p.Text.Sizing == "" {
+		p.Text.Sizing = DefaultSizing
+	}
+	if p.Text.MinSize < 0 {
+		addProblem("text.minSize", "has to be >= 0")
+	}
+
+	if p.Text.Language == "" {
+		p.Text.Language = syllable.DefaultLanguage
+	}
+	if _, err := syllable.ForLanguage(p.Text.Language); err != nil {
+		addProblem("text.language", "%s", err)
+	}
+
+	for k, iw := range p.ImageWords {
+		// Can't use iw here because it's a copy of the original object
+		if iw.Def.LineSpacingRatio == 0 {
+			p.ImageWords[k].Def.LineSpacingRatio = DefaultLineSpacingRatio
+		}
+		if iw.Def.Align == "" {
+			p.ImageWords[k].Def.Align = DefaultTextAlign
+		}
+		if text, err := parseText(iw.Text); err != nil {
+			addProblem(ImageWordPath(k, iw, "text"), "%s", err)
+		} else {
+			p.ImageWords[k].Text, p.ImageWords[k].SyllableMarks = text.text, text.marks
+			p.ImageWords[k].Styles, p.ImageWords[k].MarkupColors = text.styles, text.colors
+		}
+		if def, err := parseText(iw.Def.Text); err != nil {
+			addProblem(ImageWordPath(k, iw, "def.text"), "%s", err)
+		} else {
+			p.ImageWords[k].Def.Text, p.ImageWords[k].Def.SyllableMarks = def.text, def.marks
+			p.ImageWords[k].Def.Styles, p.ImageWords[k].Def.MarkupColors = def.styles, def.colors
+		}
+		if iw.Fit == "" {
+			p.ImageWords[k].Fit = p.Page.Fit
+		}
+		if iw.FocalPoint == nil {
+			p.ImageWords[k].FocalPoint = p.Page.FocalPoint
+		} else if err := iw.FocalPoint.validate(); err != nil {
+			addProblem(ImageWordPath(k, iw, "focalPoint"), "%s", err)
+		}
+		if iw.Rotate%90 != 0 {
+			addProblem(ImageWordPath(k, iw, "rotate"), "%d is not a multiple of 90", iw.Rotate)
+		}
+		if iw.Crop != nil {
+			if err := iw.Crop.validate(); err != nil {
+				addProblem(ImageWordPath(k, iw, "crop"), "%s", err)
+			}
+		}
+
+		// Colors of the cell: the ones of the entry, then the ones of its category, then the ones of the page
+		category := Category{}
+		if iw.Category != "" {
+			c, ok := p.Category(iw.Category)
+			if !ok {
+				addProblem(ImageWordPath(k, iw, "category"), "unknown category %s", iw.Category)
+			}
+			category = c
+		}
+		if iw.Background == nil {
+			p.ImageWords[k].Background = category.Background
+			if category.Background == nil {
+				p.ImageWords[k].Background = p.Page.Background
+			}
+		}
+		if iw.BorderColor == nil {
+			p.ImageWords[k].BorderColor = category.BorderColor
+			if category.BorderColor == nil {
+				borderColor := p.Page.BorderColor
+				p.ImageWords[k].BorderColor = &borderColor
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestPDF_Init(t *testing.T) {
+	t.Run("invalid grid", func(t *testing.T) {
+		p := PDF{Page: Page{Cols: 0, Lines: 2}}
+		if err := p.Init(); err == nil {
+			t.Errorf("Init() expected an error when cols is 0")
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		p := PDF{
+			Page:       Page{Cols: 2, Lines: 2},
+			ImageWords: make([]ImageWord, 1),
+		}
+		if err := p.Init(); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		if p.Page.Size != DefaultPageSize {
+			t.Errorf("Init() page size = %v, want %v", p.Page.Size, DefaultPageSize)
+		}
+		if p.Page.Margins.Top() != DefaultMargins.Top() {
+			t.Errorf("Init() margin top = %v, want %v", p.Page.Margins.Top(), DefaultMargins.Top())
+		}
+		if p.Text.Ratio != .2 {
+			t.Errorf("Init() text ratio = %v, want %v", p.Text.Ratio, .2)
+		}
+		if p.ImageWords[0].Def.Align != TextAlignCenter {
+			t.Errorf("Init() def align = %v, want %v", p.ImageWords[0].Def.Align, TextAlignCenter)
+		}
+		if p.Text.Sizing != SizingUniform {
+			t.Errorf("Init() text sizing = %v, want %v", p.Text.Sizing, SizingUniform)
+		}
+		if p.Output.JPEGQuality != DefaultJPEGQuality {
+			t.Errorf("Init() output jpeg quality = %v, want %v", p.Output.JPEGQuality, DefaultJPEGQuality)
+		}
+	})
+
+	t.Run("invalid output", func(t *testing.T) {
+		for _, o := range []Output{{MaxDPI: -1}, {JPEGQuality: 101}, {JPEGQuality: -5}} {
+			p := PDF{Page: Page{Cols: 1, Lines: 1}, Output: o}
+			if err := p.Init(); err == nil {
+				t.Errorf("Init() expected an error for output %+v", o)
+			}
+		}
+	})
+
+	t.Run("image fit inherited from page", func(t *testing.T) {
+		p := PDF{
+			Page:       Page{Cols: 1, Lines: 1, Fit: FitCover},
+			ImageWords: []ImageWord{{}, {Fit: FitStretch, FocalPoint: &FocalPoint{X: 0, Y: 1}}},
+		}
+		if err := p.Init(); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		if p.ImageWords[0].Fit != FitCover || *p.ImageWords[0].FocalPoint != DefaultFocalPoint {
+			t.Errorf("Init() images[0] fit = %v %v, want %v %v", p.ImageWords[0].Fit, *p.ImageWords[0].FocalPoint, FitCover, DefaultFocalPoint)
+		}
+		if p.ImageWords[1].Fit != FitStretch || *p.ImageWords[1].FocalPoint != (FocalPoint{X: 0, Y: 1}) {
+			t.Errorf("Init() images[1] fit = %v %v, want %v %v", p.ImageWords[1].Fit, *p.ImageWords[1].FocalPoint, FitStretch, FocalPoint{X: 0, Y: 1})
+		}
+	})
+
+	t.Run("focal point out of the image", func(t *testing.T) {
+		p := PDF{
+			Page:       Page{Cols: 1, Lines: 1},
+			ImageWords: []ImageWord{{FocalPoint: &FocalPoint{X: 1.5, Y: .5}}},
+		}
+		if err := p.Init(); err == nil {
+			t.Errorf("Init() expected an error when the focal point is out of the image")
+		}
+	})
+
+	t.Run("cell colors from category", func(t *testing.T) {
+		green, orange, blue := Colors["green"], Colors["orange"], Colors["blue"]
+		p := PDF{
+			Page: Page{Cols: 1, Lines: 1, Background: &blue},
+			Categories: map[string]Category{
+				"verb": {Background: &green},
+				"noun": {Background: &orange, BorderColor: &orange},
+			},
+			ImageWords: []ImageWord{{Category: "Verb"}, {Category: "noun", Background: &blue}, {}},
+		}
+		if err := p.Init(); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		want := []struct{ background, border Color }{{green, Color{}}, {blue, orange}, {blue, Color{}}}
+		for i, w := range want {
+			iw := p.ImageWords[i]
+			if !iw.Background.Equals(w.background) || !iw.BorderColor.Equals(w.border) {
+				t.Errorf("Init() images[%d] colors = %v %v, want %v %v", i, *iw.Background, *iw.BorderColor, w.background, w.border)
+			}
+		}
+	})
+
+	t.Run("syllable marks", func(t *testing.T) {
+		p := PDF{Page: Page{Cols: 1, Lines: 1}, ImageWords: []ImageWord{{Text: "cho|co|lat"}}}
+		p.ImageWords[0].Def.Text = "du cho|co|lat"
+		if err := p.Init(); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		iw := p.ImageWords[0]
+		if iw.Text != "chocolat" || iw.Def.Text != "du chocolat" || len(iw.SyllableMarks) != 2 || iw.Def.SyllableMarks[0] != 6 {
+			t.Errorf("Init() got %q %v, %q %v", iw.Text, iw.SyllableMarks, iw.Def.Text, iw.Def.SyllableMarks)
+		}
+	})
+
+	t.Run("markup", func(t *testing.T) {
+		p := PDF{Page: Page{Cols: 1, Lines: 1}, ImageWords: []ImageWord{{Text: "**le** chat"}}}
+		p.ImageWords[0].Def.Text = `{red}un{/}\nchat`
+		if err := p.Init(); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		iw := p.ImageWords[0]
+		if iw.Text != "le chat" || iw.Styles[1] != StyleBold || iw.Def.Text != "un\nchat" || !iw.Def.MarkupColors[1].Equals(Colors["red"]) {
+			t.Errorf("Init() got %q %v, %q %v", iw.Text, iw.Styles, iw.Def.Text, iw.Def.MarkupColors)
+		}
+
+		p.ImageWords[0].Def.Text = "chat{/}"
+		if err := p.Init(); err == nil {
+			t.Errorf("Init() expected an error for a color closed without being opened")
+		}
+	})
+
+	t.Run("unsupported language", func(t *testing.T) {
+		p := PDF{Page: Page{Cols: 1, Lines: 1}, Text: Text{Language: "xx"}}
+		if err := p.Init(); err == nil {
+			t.Errorf("Init() expected an error for an unsupported language")
+		}
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		p := PDF{Page: Page{Cols: 1, Lines: 1}, ImageWords: []ImageWord{{Category: "adverb"}}}
+		if err := p.Init(); err == nil {
+			t.Errorf("Init() expected an error for an unknown category")
+		}
+	})
+
+	t.Run("invalid image transforms", func(t *testing.T) {
+		for _, iw := range []ImageWord{
+			{Rotate: 45},
+			{Crop: &Crop{X: -1, Y: 0, W: 10, H: 10}},
+			{Crop: &Crop{W: 0, H: 10}},
+		} {
+			p := PDF{Page: Page{Cols: 1, Lines: 1}, ImageWords: []ImageWord{iw}}
+			if err := p.Init(); err == nil {
+				t.Errorf("Init() expected an error for rotate %d and crop %v", iw.Rotate, iw.Crop)
+			}
+		}
+	})
+
+	t.Run("negative minimum text size", func(t *testing.T) {
+		p := PDF{Page: Page{Cols: 1, Lines: 1}, Text: Text{Sizing: SizingPerCell, MinSize: -1}}
+		if err := p.Init(); err == nil {
+			t.Errorf("Init() expected an error when text.minSize is negative")
+		}
+	})
+
+	t.Run("problems located", func(t *testing.T) {
+		p := PDF{Page: Page{Cols: 0, Lines: 1}}
+		problems := DecodeProblems(p.Init())
+		if len(problems) != 1 || problems[0].Path != "page" {
+			t.Errorf("Init() problems = %v, want one for page", problems)
+		}
+		if p.Page.Size != DefaultPageSize {
+			t.Errorf("Init() valid fields should still be initialized")
+		}
+
+		p = PDF{
+			Page:       Page{Cols: 1, Lines: 1},
+			ImageWords: []ImageWord{{Rotate: 45}, {Text: "chat{/}", Origin: "words.csv:3"}},
+		}
+		problems = DecodeProblems(p.Init())
+		want := []string{"images[0].rotate", "words.csv:3 (text)"}
+		if len(problems) != len(want) {
+			t.Fatalf("Init() problems = %v, want paths %v", problems, want)
+		}
+		for i, path := range want {
+			if problems[i].Path != path {
+				t.Errorf("Init() problems[%d] = %s, want path %s", i, problems[i], path)
+			}
+		}
+		if p.ImageWords[1].Text != "chat{/}" || p.ImageWords[0].Def.Align != TextAlignCenter {
+			t.Errorf("Init() got text %q and align %s, want the raw text to be kept and valid fields initialized", p.ImageWords[1].Text, p.ImageWords[0].Def.Align)
+		}
+	})
+
+	t.Run("deprecated two-sided offset in mm", func(t *testing.T) {
+		p := PDF{Page: Page{Cols: 1, Lines: 1}}
+		p.Page.TwoSidedOffsetMM.X = 10
+		if err := p.Init(); err != nil {
+			t.Fatalf("Init() error = %v", err)
+		}
+		if want := Length(UnitMM.ToPoints(10)); p.Page.TwoSidedOffset.X != want {
+			t.Errorf("Init() two-sided offset x = %v, want %v", p.Page.TwoSidedOffset.X, want)
+		}
+	})
+}
+
+func TestDecode(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "words.csv"), []byte("image,text\na.png,chat\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "config.yaml")
+	yaml := "page:\n  orientation: diagonal\nimages_from: words.csv\n"
+	if err := ioutil.WriteFile(file, []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Decode(file)
+	if len(cfg.ImageWords) != 1 || cfg.ImageWords[0].Image != filepath.Join(dir, "a.png") {
+		t.Errorf("Decode() images = %v, want the one of the CSV file relative to the configuration file", cfg.ImageWords)
+	}
+	if problems := DecodeProblems(err); len(problems) != 1 || problems[0].Path != "page.orientation" {
+		t.Errorf("Decode() problems = %v, want page.orientation", problems)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "words.csv"), []byte("image,text,def.color\na.png,chat,nocolor\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Decode(file)
+	if problems := DecodeProblems(err); len(problems) != 2 {
+		t.Errorf("Decode() problems = %v, want the ones of the configuration and of the CSV file", problems)
+	}
+}
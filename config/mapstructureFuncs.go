@@ -5,13 +5,14 @@ import (
 	"github.com/Maldris/mathparse"
 	"github.com/mitchellh/mapstructure"
 	"reflect"
-	"strconv"
+	"sort"
 	"strings"
 )
 
 func MapstructureStringToFloat64Expr() mapstructure.DecodeHookFunc {
 	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
-		if f.Kind() != reflect.String || t.Kind() != reflect.Float64 {
+		// Lengths are handled by MapstructureStringToLength
+		if f.Kind() != reflect.String || t.Kind() != reflect.Float64 || t == reflect.TypeOf(Length(0)) {
 			return data, nil
 		}
 
@@ -24,6 +25,36 @@ func MapstructureStringToFloat64Expr() mapstructure.DecodeHookFunc {
 	}
 }
 
+// MapstructureStringToLength decodes a length expression (e.g. "5mm", "0.25in", "5.67mm / 2") to points
+func MapstructureStringToLength() mapstructure.DecodeHookFunc {
+	return stringToLength(UnitPT)
+}
+
+// stringToLength decodes a length to points, values without any unit being expressed in defaultUnit
+func stringToLength(defaultUnit Unit) mapstructure.DecodeHookFunc {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if t != reflect.TypeOf(Length(0)) {
+			return data, nil
+		}
+
+		switch f.Kind() {
+		case reflect.String:
+			val, err := ParseLength(data.(string), defaultUnit)
+			if err != nil {
+				return nil, err
+			}
+			return Length(val), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			val := reflect.ValueOf(data).Convert(reflect.TypeOf(float64(0))).Float()
+			return Length(defaultUnit.ToPoints(val)), nil
+		}
+
+		return data, nil
+	}
+}
+
 func parseMathExpression(expr string) (*float64, error) {
 	p := mathparse.NewParser(expr)
 	p.Resolve()
@@ -41,29 +72,7 @@ func MapstructureStringToColor() mapstructure.DecodeHookFunc {
 			return data, nil
 		}
 
-		raw := data.(string)
-
-		// Trying default values
-		c, ok := Colors[raw]
-		if ok {
-			return c, nil
-		}
-
-		// Trying to decode string as a color
-		strs := strings.Split(raw, ",")
-		if len(strs) != 3 {
-			return nil, fmt.Errorf("unable to decode color %s (format: r,g,b)", raw)
-		}
-		rgb := make([]uint8, 3, 3)
-		for i := 0; i < 3; i++ {
-			ui64, err := strconv.ParseUint(strs[i], 16, 8)
-			if err != nil {
-				return nil, fmt.Errorf("unable to decode color value %s", strs[0])
-			}
-			rgb[i] = uint8(ui64)
-		}
-
-		return Color{rgb[0], rgb[1], rgb[2]}, nil
+		return ParseColor(data.(string))
 	}
 }
 
@@ -99,3 +108,140 @@ func MapstructureStringToTextAlign() mapstructure.DecodeHookFunc {
 		return TextAlign(raw), nil
 	}
 }
+
+func MapstructureStringToSizing() mapstructure.DecodeHookFunc {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t != reflect.TypeOf(Sizing("")) {
+			return data, nil
+		}
+
+		raw := data.(string)
+		if raw != string(SizingUniform) && raw != string(SizingPerCell) {
+			return nil, fmt.Errorf("sizing can only be uniform or per-cell")
+		}
+
+		return Sizing(raw), nil
+	}
+}
+
+func MapstructureStringToFit() mapstructure.DecodeHookFunc {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t != reflect.TypeOf(Fit("")) {
+			return data, nil
+		}
+
+		raw := data.(string)
+		if raw != string(FitContain) && raw != string(FitCover) && raw != string(FitStretch) {
+			return nil, fmt.Errorf("fit can only be contain, cover or stretch")
+		}
+
+		return Fit(raw), nil
+	}
+}
+
+// MapstructureToPageSize decodes a page size either from a preset name (e.g. A4, Letter)
+// or from a map with width, height and an optional unit (pt if not provided)
+func MapstructureToPageSize() mapstructure.DecodeHookFunc {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if t != reflect.TypeOf(PageSize{}) {
+			return data, nil
+		}
+
+		switch f.Kind() {
+		case reflect.String:
+			raw := data.(string)
+			for name, size := range PageSizes {
+				if strings.EqualFold(name, raw) {
+					return size, nil
+				}
+			}
+			return nil, fmt.Errorf("unknown page size %s (available: %s)", raw, strings.Join(pageSizeNames(), ", "))
+		case reflect.Map:
+			// Unit applies to width and height when they don't specify any unit themselves
+			rawUnit := struct {
+				Unit string `mapstructure:"unit"`
+			}{}
+			if err := mapstructure.Decode(data, &rawUnit); err != nil {
+				return nil, fmt.Errorf("unable to decode page size: %w", err)
+			}
+			unit, err := ParseUnit(rawUnit.Unit)
+			if err != nil {
+				return nil, err
+			}
+
+			raw := struct {
+				Width  Length `mapstructure:"width"`
+				Height Length `mapstructure:"height"`
+			}{}
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				DecodeHook: stringToLength(unit),
+				Result:     &raw,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := decoder.Decode(data); err != nil {
+				return nil, fmt.Errorf("unable to decode page size: %w", err)
+			}
+
+			if raw.Width <= 0 || raw.Height <= 0 {
+				return nil, fmt.Errorf("page size width and height have to be > 0")
+			}
+
+			return PageSize{
+				W: float64(raw.Width),
+				H: float64(raw.Height),
+			}, nil
+		}
+
+		return data, nil
+	}
+}
+
+func pageSizeNames() []string {
+	names := make([]string, 0, len(PageSizes))
+	for name := range PageSizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MapstructureToTextColors decodes text colors keyed by index or range of indexes (e.g. "0-3": red)
+func MapstructureToTextColors() mapstructure.DecodeHookFunc {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.Map || t != reflect.TypeOf(TextColors{}) {
+			return data, nil
+		}
+
+		colors := map[string]Color{}
+		iter := reflect.ValueOf(data).MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			switch v := iter.Value().Interface().(type) {
+			case Color:
+				colors[key] = v
+			case string:
+				c, err := ParseColor(v)
+				if err != nil {
+					return nil, fmt.Errorf("text color %s: %w", key, err)
+				}
+				colors[key] = c
+			default:
+				return nil, fmt.Errorf("text color %s: invalid color %v", key, v)
+			}
+		}
+		return newTextColors(colors)
+	}
+}
+
+// MapstructureStringToImagesFrom decodes a file name as an ImagesFrom using default settings
+func MapstructureStringToImagesFrom() mapstructure.DecodeHookFunc {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t != reflect.TypeOf(ImagesFrom{}) {
+			return data, nil
+		}
+
+		return ImagesFrom{File: data.(string)}, nil
+	}
+}
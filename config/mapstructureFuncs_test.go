@@ -1,6 +1,8 @@
 package config
 
 import (
+	"github.com/mitchellh/mapstructure"
+	"reflect"
 	"testing"
 )
 
@@ -55,3 +57,126 @@ func Test_parseMathExpression(t *testing.T) {
 		})
 	}
 }
+
+func TestMapstructureToPageSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    interface{}
+		want    PageSize
+		wantErr bool
+	}{
+		{
+			name: "preset",
+			data: "a5",
+			want: PageSizes["A5"],
+		},
+		{
+			name:    "unknown preset",
+			data:    "B12",
+			wantErr: true,
+		},
+		{
+			name: "explicit size in points",
+			data: map[string]interface{}{"width": 100, "height": "50 * 2"},
+			want: PageSize{W: 100, H: 100},
+		},
+		{
+			name: "explicit size in inches",
+			data: map[string]interface{}{"width": 4, "height": 6, "unit": "in"},
+			want: PageSize{W: 288, H: 432},
+		},
+		{
+			name: "explicit size with units",
+			data: map[string]interface{}{"width": "1in", "height": "2in", "unit": "mm"},
+			want: PageSize{W: 72, H: 144},
+		},
+		{
+			name:    "unknown unit",
+			data:    map[string]interface{}{"width": 4, "height": 6, "unit": "furlong"},
+			wantErr: true,
+		},
+		{
+			name:    "missing height",
+			data:    map[string]interface{}{"width": 4},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PageSize{}
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				DecodeHook: MapstructureToPageSize(),
+				Result:     &got,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = decoder.Decode(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MapstructureToPageSize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("MapstructureToPageSize() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapstructureToTextColors(t *testing.T) {
+	red, blue := Colors["red"], Colors["blue"]
+	tests := []struct {
+		name    string
+		data    interface{}
+		want    TextColors
+		wantErr bool
+	}{
+		{
+			name: "indexes",
+			data: map[string]interface{}{"0": "red", "2": "blue"},
+			want: TextColors{0: red, 2: blue},
+		},
+		{
+			name: "range overridden by an index",
+			data: map[string]interface{}{"0-3": "red", "2": "blue"},
+			want: TextColors{0: red, 1: red, 2: blue, 3: red},
+		},
+		{
+			name: "int keys",
+			data: map[interface{}]interface{}{1: "blue"},
+			want: TextColors{1: blue},
+		},
+		{
+			name:    "reversed range",
+			data:    map[string]interface{}{"3-0": "red"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid color",
+			data:    map[string]interface{}{"0": "notacolor"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				TextColors TextColors `mapstructure:"textColors"`
+			}
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				DecodeHook: DecodeHook(),
+				Result:     &got,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = decoder.Decode(map[string]interface{}{"textColors": tt.data})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MapstructureToTextColors() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got.TextColors, tt.want) {
+				t.Errorf("MapstructureToTextColors() got = %v, want %v", got.TextColors, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TextStyle is the style of characters written with markup, styles being combined as flags
+type TextStyle uint8
+
+const (
+	StyleBold TextStyle = 1 << iota
+	StyleItalic
+)
+
+// TextStyles are the styles of the characters of a text, indexed by grapheme cluster
+type TextStyles map[int]TextStyle
+
+// Markup of texts: **bold**, _italic_, {color}colored{/} and \n for a line break.
+// Markers can be escaped with a backslash (e.g. \*, \_, \{ or \\) and a bold or italic marker which is never closed is kept as is.
+// Like in Markdown, underscores inside words (e.g. snake_case) are kept as is and so are braces which do not contain a color.
+const (
+	markupBold     = "**"
+	markupItalic   = "_"
+	markupColorEnd = "{/}"
+)
+
+type markupTokenKind int
+
+const (
+	markupText markupTokenKind = iota
+	markupBoldToken
+	markupItalicToken
+	markupColorToken
+	markupColorEndToken
+)
+
+// markupToken is a marker or a piece of text, pos being the rune index it starts at in the text with markup
+type markupToken struct {
+	kind  markupTokenKind
+	text  string
+	color Color
+	pos   int
+}
+
+// parsedText is a text once its syllable marks and its markup have been parsed
+type parsedText struct {
+	text   string
+	marks  []int
+	styles TextStyles
+	colors TextColors
+}
+
+// parseText strips the syllable marks and the markup of text
+func parseText(text string) (parsedText, error) {
+	stripped, marks := stripSyllableMarks(text)
+	if !strings.ContainsAny(stripped, `*_{\`) {
+		return parsedText{text: stripped, marks: marks}, nil
+	}
+
+	tokens := markupTokens(stripped)
+
+	// Rune index of each character of the text with markup once the markup is removed, to move syllable marks
+	runes := make([]int, 0, len(stripped)+1)
+	var plain []rune
+	var runeStyles []TextStyle
+	var runeColors []*Color
+
+	var style TextStyle
+	var colors []Color
+	for _, t := range tokens {
+		for len(runes) <= t.pos {
+			runes = append(runes, len(plain))
+		}
+		switch t.kind {
+		case markupBoldToken:
+			style ^= StyleBold
+		case markupItalicToken:
+			style ^= StyleItalic
+		case markupColorToken:
+			colors = append(colors, t.color)
+		case markupColorEndToken:
+			if len(colors) == 0 {
+				return parsedText{}, fmt.Errorf("invalid markup: %s without a color to close", markupColorEnd)
+			}
+			colors = colors[:len(colors)-1]
+		default:
+			var color *Color
+			if len(colors) > 0 {
+				color = &colors[len(colors)-1]
+			}
+			for _, r := range t.text {
+				plain = append(plain, r)
+				runeStyles = append(runeStyles, style)
+				runeColors = append(runeColors, color)
+			}
+		}
+	}
+	for len(runes) <= len([]rune(stripped)) {
+		runes = append(runes, len(plain))
+	}
+	for i, m := range marks {
+		marks[i] = runes[m]
+	}
+
+	// Styles and colors are expressed in grapheme clusters, the first character of a cluster giving its style
+	parsed := parsedText{text: string(plain), marks: marks}
+	indexes := graphemeIndexes(parsed.text)
+	for i := len(plain) - 1; i >= 0; i-- {
+		if runeStyles[i] != 0 {
+			if parsed.styles == nil {
+				parsed.styles = TextStyles{}
+			}
+			parsed.styles[indexes[i]] = runeStyles[i]
+		}
+		if runeColors[i] != nil {
+			if parsed.colors == nil {
+				parsed.colors = TextColors{}
+			}
+			parsed.colors[indexes[i]] = *runeColors[i]
+		}
+	}
+	return parsed, nil
+}
+
+// markupTokens splits text into markers and pieces of text.
+// Bold and italic markers which are never closed are turned into text.
+func markupTokens(text string) []markupToken {
+	runes := []rune(text)
+	tokens := make([]markupToken, 0)
+	appendText := func(s string, pos int) {
+		tokens = append(tokens, markupToken{kind: markupText, text: s, pos: pos})
+	}
+
+	for i := 0; i < len(runes); i++ {
+		rest := string(runes[i:])
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == 'n':
+			appendText("\n", i)
+			i++
+		case runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune(`*_{\`, runes[i+1]):
+			appendText(string(runes[i+1]), i)
+			i++
+		case strings.HasPrefix(rest, markupBold):
+			tokens = append(tokens, markupToken{kind: markupBoldToken, text: markupBold, pos: i})
+			i += len(markupBold) - 1
+		case strings.HasPrefix(rest, markupItalic) && !(i > 0 && isWordRune(runes[i-1]) && i+1 < len(runes) && isWordRune(runes[i+1])):
+			tokens = append(tokens, markupToken{kind: markupItalicToken, text: markupItalic, pos: i})
+		case strings.HasPrefix(rest, markupColorEnd):
+			tokens = append(tokens, markupToken{kind: markupColorEndToken, text: markupColorEnd, pos: i})
+			i += len(markupColorEnd) - 1
+		case runes[i] == '{' && isColorMarker(rest):
+			spec := []rune(rest[1:strings.IndexRune(rest, '}')])
+			color, _ := ParseColor(string(spec))
+			tokens = append(tokens, markupToken{kind: markupColorToken, color: color, pos: i})
+			i += len(spec) + 1
+		default:
+			appendText(string(runes[i]), i)
+		}
+	}
+
+	// A marker opened an odd number of times has its last occurrence kept as text
+	for _, kind := range []markupTokenKind{markupBoldToken, markupItalicToken} {
+		last, n := -1, 0
+		for i, t := range tokens {
+			if t.kind == kind {
+				last, n = i, n+1
+			}
+		}
+		if n%2 == 1 {
+			tokens[last].kind = markupText
+		}
+	}
+	return tokens
+}
+
+// isColorMarker returns whether text starts with a color between braces (e.g. {red})
+func isColorMarker(text string) bool {
+	end := strings.IndexRune(text, '}')
+	if end < 0 {
+		return false
+	}
+	_, err := ParseColor(text[1:end])
+	return err == nil
+}
+
+// isWordRune returns whether r is part of a word, an underscore between two of them not being an italic marker
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseText(t *testing.T) {
+	red, blue := Colors["red"], Colors["blue"]
+	tests := []struct {
+		name    string
+		text    string
+		want    parsedText
+		wantErr bool
+	}{
+		{name: "plain", text: "chocolat", want: parsedText{text: "chocolat"}},
+		{
+			name: "bold",
+			text: "a **big** cat",
+			want: parsedText{text: "a big cat", styles: TextStyles{2: StyleBold, 3: StyleBold, 4: StyleBold}},
+		},
+		{
+			name: "bold italic",
+			text: "**a _b_**_c_",
+			want: parsedText{text: "a bc", styles: TextStyles{0: StyleBold, 1: StyleBold, 2: StyleBold | StyleItalic, 3: StyleItalic}},
+		},
+		{
+			name: "nested colors",
+			text: "{red}a{blue}b{/}c{/}d",
+			want: parsedText{text: "abcd", colors: TextColors{0: red, 1: blue, 2: red}},
+		},
+		{name: "line break", text: `one\ntwo`, want: parsedText{text: "one\ntwo"}},
+		{name: "escaped markers", text: `\*\*a\_b\{red}`, want: parsedText{text: "**a_b{red}"}},
+		{name: "escaped backslash", text: `a\\_b_\\n\\|c`, want: parsedText{text: `a\b\n\c`, marks: []int{6}, styles: TextStyles{2: StyleItalic}}},
+		{name: "unclosed markers", text: "snake **", want: parsedText{text: "snake **"}},
+		{name: "underscores inside words", text: "snake_case_name _it_", want: parsedText{text: "snake_case_name it", styles: TextStyles{16: StyleItalic, 17: StyleItalic}}},
+		{
+			name: "syllable marks",
+			text: "**cho|co**|lat",
+			want: parsedText{text: "chocolat", marks: []int{3, 5}, styles: TextStyles{0: StyleBold, 1: StyleBold, 2: StyleBold, 3: StyleBold, 4: StyleBold}},
+		},
+		{
+			name: "grapheme clusters",
+			text: "é**té**",
+			want: parsedText{text: "été", styles: TextStyles{1: StyleBold, 2: StyleBold}},
+		},
+		{name: "braces without a color", text: "{notacolor}a{", want: parsedText{text: "{notacolor}a{"}},
+		{name: "unopened color", text: "a{/}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseText(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseText() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseText() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFontVariants_Variant(t *testing.T) {
+	v := FontVariants{FontBold: "bold.ttf", FontItalic: "italic.ttf"}
+	tests := []struct {
+		style TextStyle
+		want  string
+	}{
+		{style: 0, want: ""},
+		{style: StyleBold, want: "bold.ttf"},
+		{style: StyleItalic, want: "italic.ttf"},
+		{style: StyleBold | StyleItalic, want: "bold.ttf"},
+	}
+	for _, tt := range tests {
+		if got := v.Variant(tt.style); got != tt.want {
+			t.Errorf("Variant(%d) = %s, want %s", tt.style, got, tt.want)
+		}
+	}
+	v.FontBoldItalic = "bolditalic.ttf"
+	if got := v.Variant(StyleBold | StyleItalic); got != "bolditalic.ttf" {
+		t.Errorf("Variant(bold italic) = %s, want bolditalic.ttf", got)
+	}
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// colorFormats lists the supported color formats, used in error messages
+const colorFormats = "a CSS name, #RRGGBB, #RGB, #RRGGBBAA, #RGBA, rgb(r, g, b), rgba(r, g, b, a), hsl(h, s%, l%) or hsla(h, s%, l%, a)"
+
+// ParseColor parses a color given as a CSS name (e.g. red), as #RRGGBB or #RGB, as rgb(r, g, b) or as hsl(h, s%, l%).
+// Colors can be transparent using #RRGGBBAA, #RGBA, rgba(r, g, b, a) or hsla(h, s%, l%, a), alpha being between 0 and 1 or a percentage.
+// The legacy r,g,b form whose components are hexadecimal is still supported but deprecated.
+func ParseColor(raw string) (Color, error) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	if c, ok := Colors[s]; ok {
+		return c, nil
+	}
+
+	var c Color
+	var err error
+	switch {
+	case strings.HasPrefix(s, "#"):
+		c, err = parseHexColor(s[1:])
+	case isColorFunc(s, "rgb") || isColorFunc(s, "rgba"):
+		c, err = parseRGBColor(colorFuncArgs(s))
+	case isColorFunc(s, "hsl") || isColorFunc(s, "hsla"):
+		c, err = parseHSLColor(colorFuncArgs(s))
+	case strings.Count(s, ",") == 2:
+		c, err = parseLegacyColor(s)
+	default:
+		return Color{}, fmt.Errorf("invalid color %q, expected %s", raw, colorFormats)
+	}
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color %q: %w", raw, err)
+	}
+	return c, nil
+}
+
+// parseHexColor parses RRGGBB, RGB, RRGGBBAA or RGBA
+func parseHexColor(s string) (Color, error) {
+	digits := s
+	if len(s) == 3 || len(s) == 4 {
+		long := make([]byte, 0, len(s)*2)
+		for i := 0; i < len(s); i++ {
+			long = append(long, s[i], s[i])
+		}
+		s = string(long)
+	}
+	if len(s) == 6 {
+		s += "ff"
+	}
+	if len(s) != 8 {
+		return Color{}, fmt.Errorf("expected 3, 4, 6 or 8 hexadecimal digits after #")
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("%q is not hexadecimal", digits)
+	}
+	return Color{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), Transparency: 255 - uint8(v)}, nil
+}
+
+// isColorFunc returns true if s is a call to the color function name, e.g. rgb(...)
+func isColorFunc(s, name string) bool {
+	return strings.HasPrefix(s, name+"(") && strings.HasSuffix(s, ")")
+}
+
+// colorFuncArgs returns the arguments of a color function
+func colorFuncArgs(s string) string {
+	return s[strings.Index(s, "(")+1 : len(s)-1]
+}
+
+// colorArgs splits the arguments of a color function, separated by commas, spaces or a slash before alpha.
+// Alpha is optional and returned separately, empty if not provided.
+func colorArgs(s string, names ...string) ([]string, string, error) {
+	args := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '/' })
+	switch len(args) {
+	case len(names):
+		return args, "", nil
+	case len(names) + 1:
+		return args[:len(names)], args[len(names)], nil
+	}
+	return nil, "", fmt.Errorf("expected %d values (%s) and an optional alpha, got %d", len(names), strings.Join(names, ", "), len(args))
+}
+
+// parseAlpha parses an alpha value between 0 and 1 or a percentage, returning the transparency of a color
+func parseAlpha(s string) (uint8, error) {
+	if s == "" {
+		return 0, nil
+	}
+	max := 1.0
+	v := strings.TrimSuffix(s, "%")
+	if v != s {
+		max = 100
+	}
+	a, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("alpha %q is not a number", s)
+	}
+	if a < 0 || a > max {
+		return 0, fmt.Errorf("alpha must be between 0 and 1 or a percentage, got %s", s)
+	}
+	return 255 - uint8(math.Round(a*255/max)), nil
+}
+
+// parseRGBColor parses the arguments of rgb() or rgba(), each component being between 0 and 255 or a percentage
+func parseRGBColor(s string) (Color, error) {
+	names := []string{"red", "green", "blue"}
+	args, alpha, err := colorArgs(s, names...)
+	if err != nil {
+		return Color{}, err
+	}
+	t, err := parseAlpha(alpha)
+	if err != nil {
+		return Color{}, err
+	}
+
+	rgb := make([]uint8, 3)
+	for i, arg := range args {
+		max := 255.0
+		if strings.HasSuffix(arg, "%") {
+			arg = strings.TrimSuffix(arg, "%")
+			max = 100
+		}
+		v, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return Color{}, fmt.Errorf("%s %q is not a number", names[i], args[i])
+		}
+		if v < 0 || v > max {
+			return Color{}, fmt.Errorf("%s must be between 0 and %v, got %s", names[i], max, args[i])
+		}
+		rgb[i] = uint8(math.Round(v * 255 / max))
+	}
+	return Color{R: rgb[0], G: rgb[1], B: rgb[2], Transparency: t}, nil
+}
+
+// parseHSLColor parses the arguments of hsl() or hsla(), hue being in degrees, saturation and lightness being percentages
+func parseHSLColor(s string) (Color, error) {
+	args, alpha, err := colorArgs(s, "hue", "saturation", "lightness")
+	if err != nil {
+		return Color{}, err
+	}
+	t, err := parseAlpha(alpha)
+	if err != nil {
+		return Color{}, err
+	}
+
+	h, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "deg"), 64)
+	if err != nil {
+		return Color{}, fmt.Errorf("hue %q is not a number of degrees", args[0])
+	}
+	sl := make([]float64, 2)
+	for i, name := range []string{"saturation", "lightness"} {
+		arg := args[i+1]
+		if !strings.HasSuffix(arg, "%") {
+			return Color{}, fmt.Errorf("%s %q must be a percentage", name, arg)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSuffix(arg, "%"), 64)
+		if err != nil {
+			return Color{}, fmt.Errorf("%s %q is not a number", name, arg)
+		}
+		if v < 0 || v > 100 {
+			return Color{}, fmt.Errorf("%s must be between 0%% and 100%%, got %s", name, arg)
+		}
+		sl[i] = v / 100
+	}
+
+	c := hslToColor(h, sl[0], sl[1])
+	c.Transparency = t
+	return c, nil
+}
+
+// hslToColor converts a color from HSL, see https://www.w3.org/TR/css-color-3/#hsl-color
+func hslToColor(h, s, l float64) Color {
+	h = math.Mod(math.Mod(h, 360)+360, 360) / 360
+	var m2 float64
+	if l <= .5 {
+		m2 = l * (s + 1)
+	} else {
+		m2 = l + s - l*s
+	}
+	m1 := l*2 - m2
+
+	hueToRGB := func(h float64) uint8 {
+		if h < 0 {
+			h++
+		} else if h > 1 {
+			h--
+		}
+		var v float64
+		switch {
+		case h*6 < 1:
+			v = m1 + (m2-m1)*h*6
+		case h*2 < 1:
+			v = m2
+		case h*3 < 2:
+			v = m1 + (m2-m1)*(2./3-h)*6
+		default:
+			v = m1
+		}
+		return uint8(math.Round(v * 255))
+	}
+	return Color{R: hueToRGB(h + 1./3), G: hueToRGB(h), B: hueToRGB(h - 1./3)}
+}
+
+// parseLegacyColor parses the deprecated r,g,b form whose components are hexadecimal
+func parseLegacyColor(s string) (Color, error) {
+	names := []string{"red", "green", "blue"}
+	strs := strings.Split(s, ",")
+	rgb := make([]uint8, 3)
+	for i, str := range strs {
+		str = strings.TrimSpace(str)
+		v, err := strconv.ParseUint(str, 16, 8)
+		if err != nil {
+			// Most likely decimal values written before the hexadecimal nature of this form was known
+			if _, decErr := strconv.ParseUint(str, 10, 8); decErr == nil {
+				return Color{}, fmt.Errorf("%s %q is not a hexadecimal value between 00 and ff (r,g,b components are hexadecimal), use rgb(%s) for decimal values",
+					names[i], str, s)
+			}
+			return Color{}, fmt.Errorf("%s %q is not a hexadecimal value between 00 and ff", names[i], str)
+		}
+		rgb[i] = uint8(v)
+	}
+
+	c := Color{R: rgb[0], G: rgb[1], B: rgb[2]}
+	log.Warn().Msgf("Color %s uses the deprecated r,g,b form whose components are hexadecimal, use %s instead", s, c.Hex())
+	return c, nil
+}
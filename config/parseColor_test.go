@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    Color
+		wantErr string
+	}{
+		{name: "css name", raw: "tomato", want: Color{R: 255, G: 99, B: 71}},
+		{name: "css name is case insensitive", raw: " SteelBlue ", want: Color{R: 70, G: 130, B: 180}},
+		{name: "hex", raw: "#ff6347", want: Color{R: 255, G: 99, B: 71}},
+		{name: "short hex", raw: "#F00", want: Color{R: 255, G: 0, B: 0}},
+		{name: "rgb", raw: "rgb(255, 99, 71)", want: Color{R: 255, G: 99, B: 71}},
+		{name: "rgb with spaces only", raw: "rgb(255 99 71)", want: Color{R: 255, G: 99, B: 71}},
+		{name: "rgb percentages", raw: "rgb(100%, 0%, 50%)", want: Color{R: 255, G: 0, B: 128}},
+		{name: "hsl red", raw: "hsl(0, 100%, 50%)", want: Color{R: 255, G: 0, B: 0}},
+		{name: "hsl tomato", raw: "hsl(9deg, 100%, 64%)", want: Color{R: 255, G: 99, B: 71}},
+		{name: "hsl gray", raw: "hsl(120, 0%, 50%)", want: Color{R: 128, G: 128, B: 128}},
+		{name: "hsl negative hue", raw: "hsl(-120, 100%, 50%)", want: Color{R: 0, G: 0, B: 255}},
+		{name: "hex with alpha", raw: "#ff634780", want: Color{R: 255, G: 99, B: 71, Transparency: 127}},
+		{name: "short hex with alpha", raw: "#f000", want: Color{R: 255, Transparency: 255}},
+		{name: "rgba", raw: "rgba(255, 99, 71, 0.5)", want: Color{R: 255, G: 99, B: 71, Transparency: 127}},
+		{name: "rgb with slash alpha", raw: "rgb(255 99 71 / 25%)", want: Color{R: 255, G: 99, B: 71, Transparency: 191}},
+		{name: "hsla", raw: "hsla(0, 100%, 50%, 1)", want: Color{R: 255}},
+		{name: "legacy hex components", raw: "ff,63,47", want: Color{R: 255, G: 99, B: 71}},
+		{name: "unknown name", raw: "notacolor", wantErr: `invalid color "notacolor", expected a CSS name`},
+		{name: "invalid hex length", raw: "#ff000", wantErr: "expected 3, 4, 6 or 8 hexadecimal digits"},
+		{name: "invalid hex digits", raw: "#gg0000", wantErr: `"gg0000" is not hexadecimal`},
+		{name: "rgb out of range", raw: "rgb(0, 300, 0)", wantErr: "green must be between 0 and 255, got 300"},
+		{name: "rgb missing component", raw: "rgb(0, 0)", wantErr: "expected 3 values (red, green, blue) and an optional alpha, got 2"},
+		{name: "rgb not a number", raw: "rgb(0, 0, x)", wantErr: `blue "x" is not a number`},
+		{name: "alpha out of range", raw: "rgba(0, 0, 0, 2)", wantErr: "alpha must be between 0 and 1 or a percentage, got 2"},
+		{name: "hsl without percentage", raw: "hsl(0, 100, 50%)", wantErr: `saturation "100" must be a percentage`},
+		{name: "legacy decimal components", raw: "255,0,0", wantErr: `red "255" is not a hexadecimal value between 00 and ff (r,g,b components are hexadecimal), use rgb(255,0,0)`},
+		{name: "legacy invalid component", raw: "ff,zz,0", wantErr: `green "zz" is not a hexadecimal value`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseColor(tt.raw)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseColor() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ParseColor() unexpected error = %v", err)
+				return
+			}
+			if !got.Equals(tt.want) {
+				t.Errorf("ParseColor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColor_MarshalYAML(t *testing.T) {
+	for _, tt := range []struct {
+		color Color
+		want  string
+	}{
+		{color: Color{R: 255, G: 99, B: 71}, want: "tomato"},
+		{color: Color{R: 1, G: 2, B: 171}, want: "#0102AB"},
+		{color: Color{R: 255, G: 99, B: 71, Transparency: 127}, want: "#FF634780"},
+	} {
+		got, err := tt.color.MarshalYAML()
+		if err != nil || got != tt.want {
+			t.Errorf("MarshalYAML() = %v, %v, want %v", got, err, tt.want)
+		}
+	}
+}
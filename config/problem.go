@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"regexp"
+	"strings"
+)
+
+// mapstructureErrorRegexps extract the field name and the message from mapstructure errors
+var mapstructureErrorRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`^error decoding '([^']*)': (.*)$`),
+	regexp.MustCompile(`^'([^']*)' (.*)$`),
+}
+
+// Problem is an issue found in a configuration
+type Problem struct {
+	// Path is the YAML path of the offending entry (e.g. images[2].image)
+	Path    string
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("%s: %s", p.Path, p.Message)
+}
+
+// Problems are all the issues found in a configuration, returned as a single error
+type Problems []Problem
+
+func (ps Problems) Error() string {
+	messages := make([]string, 0, len(ps))
+	for _, p := range ps {
+		messages = append(messages, p.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ImageWordPath returns the path of a field of the i-th image word, using its origin if it has been loaded from a file
+func ImageWordPath(i int, iw ImageWord, field string) string {
+	if iw.Origin != "" {
+		return fmt.Sprintf("%s (%s)", iw.Origin, field)
+	}
+	return fmt.Sprintf("images[%d].%s", i, field)
+}
+
+// DecodeProblems returns a problem for each field which could not be decoded,
+// or the problems themselves if err is Problems (e.g. returned by PDF.Init)
+func DecodeProblems(err error) []Problem {
+	if err == nil {
+		return nil
+	}
+
+	var problems Problems
+	if errors.As(err, &problems) {
+		return problems
+	}
+
+	var msErr *mapstructure.Error
+	if !errors.As(err, &msErr) {
+		return []Problem{{Message: err.Error()}}
+	}
+
+	problems = make([]Problem, 0, len(msErr.Errors))
+	for _, e := range msErr.Errors {
+		p := Problem{Message: e}
+		for _, re := range mapstructureErrorRegexps {
+			if m := re.FindStringSubmatch(e); m != nil {
+				p = Problem{Path: m[1], Message: m[2]}
+				break
+			}
+		}
+		problems = append(problems, p)
+	}
+	return problems
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"github.com/mitchellh/mapstructure"
+	"testing"
+)
+
+func TestDecodeProblems(t *testing.T) {
+	data := map[string]interface{}{
+		"page": map[string]interface{}{
+			"orientation": "diagonal",
+		},
+		"images": []interface{}{
+			map[string]interface{}{"image": "a.png", "text": "a"},
+			map[string]interface{}{"image": "b.png", "def": map[string]interface{}{"align": "right"}},
+		},
+	}
+
+	cfg := PDF{}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: DecodeHook(),
+		Result:     &cfg,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems := DecodeProblems(decoder.Decode(data))
+	want := map[string]bool{
+		"page.orientation":    false,
+		"images[1].def.align": false,
+	}
+	for _, p := range problems {
+		if _, ok := want[p.Path]; !ok {
+			t.Errorf("DecodeProblems() unexpected problem %s", p)
+			continue
+		}
+		want[p.Path] = true
+	}
+	for path, found := range want {
+		if !found {
+			t.Errorf("DecodeProblems() no problem found for %s", path)
+		}
+	}
+
+	if len(DecodeProblems(nil)) != 0 {
+		t.Errorf("DecodeProblems(nil) should not return any problem")
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"github.com/nmaupu/gopicto/syllable"
+	"strings"
+)
+
+// syllableMark separates syllables in texts to override the automatic syllabification (e.g. cho|co|lat)
+const syllableMark = '|'
+
+// stripSyllableMarks removes the syllable marks of text and returns the rune index each marked syllable starts at.
+// An escaped mark (\|) is kept as a literal |.
+func stripSyllableMarks(text string) (string, []int) {
+	if !strings.ContainsRune(text, syllableMark) {
+		return text, nil
+	}
+
+	var marks []int
+	runes := []rune(text)
+	stripped := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == syllableMark:
+			stripped = append(stripped, syllableMark)
+			i++
+		case runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == '\\':
+			// Escaped backslash, kept for the markup to unescape it
+			stripped = append(stripped, runes[i], runes[i+1])
+			i++
+		case runes[i] == syllableMark:
+			marks = append(marks, len(stripped))
+		default:
+			stripped = append(stripped, runes[i])
+		}
+	}
+	return string(stripped), marks
+}
+
+// syllableColors returns the colors of the characters of text, syllables being colored alternately with t.SyllableColors
+func (t Text) syllableColors(text string, marks []int) TextColors {
+	colors := TextColors{}
+	if len(t.SyllableColors) == 0 {
+		return colors
+	}
+	s, err := syllable.ForLanguage(t.Language)
+	if err != nil { // checked when initializing the configuration
+		return colors
+	}
+
+	// Syllables and marks are expressed in runes, colors in grapheme clusters
+	indexes := graphemeIndexes(text)
+	for k, syl := range syllable.Syllables(text, s, marks) {
+		for i := indexes[syl[0]]; i < indexes[syl[1]]; i++ {
+			colors[i] = t.SyllableColors[k%len(t.SyllableColors)]
+		}
+	}
+	return colors
+}
+
+// PictoTextColors returns the colors of the characters of the text of iw:
+// its text colors, then the colors of its markup, then the color rules, then the syllable colors
+func (t Text) PictoTextColors(iw ImageWord) TextColors {
+	explicit := iw.MarkupColors.with(iw.TextColors)
+	return t.syllableColors(iw.Text, iw.SyllableMarks).with(t.ColorRules.Resolve(iw.Text, explicit))
+}
+
+// DefinitionTextColors returns the colors of the characters of the definition of iw:
+// its text colors, then the colors of its markup, then the syllable colors
+func (t Text) DefinitionTextColors(iw ImageWord) TextColors {
+	return t.syllableColors(iw.Def.Text, iw.Def.SyllableMarks).with(iw.Def.MarkupColors.with(iw.Def.TextColors))
+}
+
+// with returns the colors of c overridden by the ones of over
+func (c TextColors) with(over TextColors) TextColors {
+	if len(c) == 0 {
+		return over
+	}
+	colors := make(TextColors, len(c)+len(over))
+	for i, col := range c {
+		colors[i] = col
+	}
+	for i, col := range over {
+		colors[i] = col
+	}
+	return colors
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_stripSyllableMarks(t *testing.T) {
+	tests := []struct {
+		text      string
+		want      string
+		wantMarks []int
+	}{
+		{text: "chocolat", want: "chocolat"},
+		{text: "cho|co|lat", want: "chocolat", wantMarks: []int{3, 5}},
+		{text: "un é|lé|phant", want: "un éléphant", wantMarks: []int{4, 6}},
+		{text: `a \| b`, want: "a | b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, marks := stripSyllableMarks(tt.text)
+			if got != tt.want || !reflect.DeepEqual(marks, tt.wantMarks) {
+				t.Errorf("stripSyllableMarks() = %q %v, want %q %v", got, marks, tt.want, tt.wantMarks)
+			}
+		})
+	}
+}
+
+func TestText_PictoTextColors(t *testing.T) {
+	red, blue, green := Colors["red"], Colors["blue"], Colors["green"]
+	text := Text{Language: "fr", SyllableColors: []Color{red, blue}, ColorRules: ColorRules{LastLetter: &green}}
+
+	iw := ImageWord{Text: "chocolat", SyllableMarks: []int{4}, TextColors: TextColors{0: green}}
+	want := TextColors{0: green, 1: red, 2: red, 3: red, 4: blue, 5: blue, 6: blue, 7: green}
+	if got := text.PictoTextColors(iw); !reflect.DeepEqual(got, want) {
+		t.Errorf("PictoTextColors() = %v, want %v", got, want)
+	}
+
+	iw.Def.Text = "le lapin"
+	want = TextColors{0: red, 1: red, 3: blue, 4: blue, 5: red, 6: red, 7: red}
+	if got := text.DefinitionTextColors(iw); !reflect.DeepEqual(got, want) {
+		t.Errorf("DefinitionTextColors() = %v, want %v", got, want)
+	}
+}
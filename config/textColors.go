@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// textColorRange parses the key of a text color: an index (e.g. 3) or an inclusive range of indexes (e.g. 0-3)
+func textColorRange(key string) (int, int, error) {
+	from, to := key, key
+	if i := strings.Index(key, "-"); i > 0 {
+		from, to = key[:i], key[i+1:]
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(from))
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("invalid text color index %s (format: index or first-last)", key)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(to))
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("invalid text color range %s (format: first-last with first <= last)", key)
+	}
+	return start, end, nil
+}
+
+// newTextColors returns text colors from colors keyed by index or range of indexes.
+// Ranges are applied from the widest to the narrowest so that a single index overrides the ranges it belongs to.
+func newTextColors(colors map[string]Color) (TextColors, error) {
+	type textColorSpec struct {
+		start, end int
+		color      Color
+	}
+	specs := make([]textColorSpec, 0, len(colors))
+	for key, color := range colors {
+		start, end, err := textColorRange(key)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, textColorSpec{start, end, color})
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		if wi, wj := specs[i].end-specs[i].start, specs[j].end-specs[j].start; wi != wj {
+			return wi > wj
+		}
+		return specs[i].start < specs[j].start
+	})
+
+	textColors := TextColors{}
+	for _, s := range specs {
+		for i := s.start; i <= s.end; i++ {
+			textColors[i] = s.color
+		}
+	}
+	return textColors, nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTextColors(t *testing.T) {
+	red, blue := Colors["red"], Colors["blue"]
+	tests := []struct {
+		name    string
+		raw     string
+		want    TextColors
+		wantErr bool
+	}{
+		{name: "empty", raw: "", want: TextColors{}},
+		{name: "indexes", raw: "0:red;2:blue", want: TextColors{0: red, 2: blue}},
+		{name: "range", raw: " 1-3 : red ", want: TextColors{1: red, 2: red, 3: red}},
+		{name: "index overrides range", raw: "2:blue;0-3:red", want: TextColors{0: red, 1: red, 2: blue, 3: red}},
+		{name: "narrower range overrides wider one", raw: "0-1:blue;0-3:red", want: TextColors{0: blue, 1: blue, 2: red, 3: red}},
+		{name: "missing color", raw: "0", wantErr: true},
+		{name: "negative index", raw: "-1:red", wantErr: true},
+		{name: "reversed range", raw: "3-1:red", wantErr: true},
+		{name: "invalid range", raw: "1-x:red", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTextColors(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTextColors() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTextColors() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "ascii", text: "abc", want: []string{"a", "b", "c"}},
+		{name: "combining accent", text: "e\u0301te\u0301", want: []string{"e\u0301", "t", "e\u0301"}},
+		{name: "emoji", text: "a👍🏽b", want: []string{"a", "👍🏽", "b"}},
+		{name: "empty", text: "", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Graphemes(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Graphemes() = %q, want %q", got, tt.want)
+			}
+			if got := graphemeIndexes(tt.text); got[len(got)-1] != len(tt.want) {
+				t.Errorf("graphemeIndexes() count = %d, want %d", got[len(got)-1], len(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	UnitPT = Unit("pt")
+	UnitMM = Unit("mm")
+	UnitCM = Unit("cm")
+	UnitIN = Unit("in")
+)
+
+// Unit is a length unit which can be converted to points
+type Unit string
+
+// Length is a length expressed in points
+type Length float64
+
+// lengthRegexp matches a number and its unit if any (e.g. 5mm, 0.25 in, .5cm or 2)
+var lengthRegexp = regexp.MustCompile(`(\d+(?:\.\d*)?|\.\d+)(?:\s*(pt|mm|cm|in)\b)?`)
+
+// pointsPerUnit gives how many points there are in one unit
+var pointsPerUnit = map[Unit]float64{
+	UnitPT: 1,
+	UnitMM: 72 / 25.4,
+	UnitCM: 72 / 2.54,
+	UnitIN: 72,
+}
+
+// ParseUnit returns the Unit corresponding to the given string, an empty string being points
+func ParseUnit(s string) (Unit, error) {
+	u := Unit(strings.ToLower(strings.TrimSpace(s)))
+	if u == "" {
+		return UnitPT, nil
+	}
+	if _, ok := pointsPerUnit[u]; !ok {
+		return "", fmt.Errorf("unknown unit %s (available: pt, mm, cm, in)", s)
+	}
+	return u, nil
+}
+
+// ToPoints converts v expressed in u to points
+func (u Unit) ToPoints(v float64) float64 {
+	ratio, ok := pointsPerUnit[u]
+	if !ok {
+		return v
+	}
+	return v * ratio
+}
+
+// ParseLength evaluates expr and returns its value in points.
+// expr is a math expression where numbers can be suffixed by a unit (e.g. "5.67mm / 2" or "1in + 2mm").
+// If no unit is used at all in expr, the result is considered to be expressed in defaultUnit.
+// Otherwise numbers without a unit can only multiply or divide lengths: "2mm + 3" is rejected.
+func ParseLength(expr string, defaultUnit Unit) (float64, error) {
+	lower := strings.ToLower(expr)
+	var converted strings.Builder
+	hasUnit, hasTermWithoutUnit := false, false
+	last := 0
+	for _, m := range lengthRegexp.FindAllStringSubmatchIndex(lower, -1) {
+		converted.WriteString(lower[last:m[0]])
+		last = m[1]
+		number := lower[m[2]:m[3]]
+		if m[4] < 0 {
+			// A number which is neither multiplied nor divided is a term of a sum
+			before, after := strings.TrimSpace(lower[:m[0]]), strings.TrimSpace(lower[m[1]:])
+			if !strings.HasSuffix(before, "*") && !strings.HasSuffix(before, "/") &&
+				!strings.HasPrefix(after, "*") && !strings.HasPrefix(after, "/") {
+				hasTermWithoutUnit = true
+			}
+			converted.WriteString(number)
+			continue
+		}
+
+		hasUnit = true
+		v, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse length %s: %w", expr, err)
+		}
+		converted.WriteString(strconv.FormatFloat(Unit(lower[m[4]:m[5]]).ToPoints(v), 'f', -1, 64))
+	}
+	converted.WriteString(lower[last:])
+	if hasUnit && hasTermWithoutUnit {
+		return 0, fmt.Errorf("unable to parse length %s: numbers without a unit cannot be added to lengths (e.g. 2mm + 3pt instead of 2mm + 3)", expr)
+	}
+
+	val, err := parseMathExpression(converted.String())
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse length %s", expr)
+	}
+	if hasUnit {
+		return *val, nil
+	}
+	return defaultUnit.ToPoints(*val), nil
+}
+
+func lengthptr(l Length) *Length {
+	return &l
+}
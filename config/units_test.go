@@ -0,0 +1,95 @@
+package config
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseLength(t *testing.T) {
+	tests := []struct {
+		name        string
+		expr        string
+		defaultUnit Unit
+		want        float64
+		wantErr     bool
+	}{
+		{
+			name:        "bare number is in default unit",
+			expr:        "12",
+			defaultUnit: UnitPT,
+			want:        12,
+		},
+		{
+			name:        "bare expression in mm",
+			expr:        "10 / 2",
+			defaultUnit: UnitMM,
+			want:        5 * 72 / 25.4,
+		},
+		{
+			name:        "millimeters",
+			expr:        "5mm",
+			defaultUnit: UnitPT,
+			want:        5 * 72 / 25.4,
+		},
+		{
+			name:        "inches",
+			expr:        "0.25in",
+			defaultUnit: UnitPT,
+			want:        18,
+		},
+		{
+			name:        "expression with unit",
+			expr:        "5.67mm / 2",
+			defaultUnit: UnitPT,
+			want:        5.67 * 72 / 25.4 / 2,
+		},
+		{
+			name:        "explicit unit ignores default unit",
+			expr:        "1cm + 12pt",
+			defaultUnit: UnitIN,
+			want:        72/2.54 + 12,
+		},
+		{
+			name:        "negative value",
+			expr:        "-3mm",
+			defaultUnit: UnitPT,
+			want:        -3 * 72 / 25.4,
+		},
+		{
+			name:        "unit in parentheses multiplied by a number",
+			expr:        "(1cm + 2mm) * 2",
+			defaultUnit: UnitPT,
+			want:        (72/2.54 + 2*72/25.4) * 2,
+		},
+		{
+			name:        "number without unit added to a length",
+			expr:        "2mm + 3",
+			defaultUnit: UnitPT,
+			wantErr:     true,
+		},
+		{
+			name:        "number without unit subtracted in parentheses",
+			expr:        "(2mm - 1) / 2",
+			defaultUnit: UnitPT,
+			wantErr:     true,
+		},
+		{
+			name:        "unknown unit",
+			expr:        "3km",
+			defaultUnit: UnitPT,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLength(tt.expr, tt.defaultUnit)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseLength() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ParseLength() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
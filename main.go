@@ -12,5 +12,6 @@ func main() {
 	err := cli.Execute()
 	if err != nil {
 		log.Error().Err(err).Msg("An error occurred")
+		os.Exit(1)
 	}
 }
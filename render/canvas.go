@@ -0,0 +1,185 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/nmaupu/gopdf"
+	"github.com/nmaupu/gopicto/config"
+	"github.com/rs/zerolog/log"
+	"io"
+)
+
+// canvas is a drawing surface all the outputs (PDF, raster images, SVG) are rendered on.
+// Coordinates and sizes are in points from the upper left corner of the page,
+// y being the baseline of text.
+type canvas interface {
+	AddPage() error
+	AddTTFFont(family string, path string) error
+	AddTTFFontData(family string, data []byte) error
+	SetFont(family string, size float64) error
+	MeasureTextWidth(text string) (float64, error)
+	SplitTextWithWordWrap(text string, width float64) ([]string, error)
+	SetXY(x, y float64)
+	// Text prints text at the current position and moves it to the end of text
+	Text(text string, color config.Color) error
+	SetLineWidth(width float64)
+	SetLineType(lineType string)
+	// SetLineColor sets the color of lines and rectangles drawn afterwards, black by default
+	SetLineColor(color config.Color)
+	Line(x1, y1, x2, y2 float64)
+	RectFromUpperLeft(x, y, w, h float64)
+	FillRect(x, y, w, h float64, color config.Color)
+	Image(pic *picture, x, y, w, h float64) error
+	// ClipRect restricts drawing to a rectangle until ResetClip is called
+	ClipRect(x, y, w, h float64)
+	ResetClip()
+	// Close flushes everything which has not been written yet
+	Close() error
+}
+
+// pdfCanvas draws on a PDF document
+type pdfCanvas struct {
+	pdf       *gopdf.GoPdf
+	w         io.Writer
+	lineColor config.Color
+}
+
+func newPdfCanvas(pageSize gopdf.Rect, w io.Writer) *pdfCanvas {
+	pdf := &gopdf.GoPdf{}
+	// Unit is pt as gopdf's unit support seems to be broken
+	pdf.Start(gopdf.Config{
+		PageSize: pageSize,
+		//Unit:     gopdf.UnitMM,
+	})
+	return &pdfCanvas{pdf: pdf, w: w}
+}
+
+func (c *pdfCanvas) AddPage() error {
+	c.pdf.AddPage()
+	return nil
+}
+
+func (c *pdfCanvas) AddTTFFont(family string, path string) error {
+	return c.pdf.AddTTFFont(family, path)
+}
+
+func (c *pdfCanvas) AddTTFFontData(family string, data []byte) error {
+	return c.pdf.AddTTFFontByReader(family, bytes.NewReader(data))
+}
+
+func (c *pdfCanvas) SetFont(family string, size float64) error {
+	if err := c.pdf.SetFont(family, "", size); err != nil {
+		return err
+	}
+	return c.pdf.SetFontSize(size)
+}
+
+func (c *pdfCanvas) MeasureTextWidth(text string) (float64, error) {
+	return c.pdf.MeasureTextWidth(text)
+}
+
+func (c *pdfCanvas) SplitTextWithWordWrap(text string, width float64) ([]string, error) {
+	return c.pdf.SplitTextWithWordWrap(text, width)
+}
+
+func (c *pdfCanvas) SetXY(x, y float64) {
+	c.pdf.SetX(x)
+	c.pdf.SetY(y)
+}
+
+func (c *pdfCanvas) Text(text string, color config.Color) error {
+	c.pdf.SetTextColor(color.AsUints())
+	return c.withAlpha(color, func() error {
+		return c.pdf.Text(text)
+	})
+}
+
+// withAlpha calls draw with the opacity of color applied
+func (c *pdfCanvas) withAlpha(color config.Color, draw func() error) error {
+	if color.IsOpaque() {
+		return draw()
+	}
+
+	err := c.pdf.SetTransparency(gopdf.Transparency{Alpha: color.Opacity(), BlendModeType: gopdf.NormalBlendMode})
+	if err != nil {
+		return fmt.Errorf("unable to set transparency: %w", err)
+	}
+	defer c.pdf.ClearTransparency()
+	return draw()
+}
+
+// stroke draws lines with the current line color
+func (c *pdfCanvas) stroke(draw func()) {
+	c.pdf.SetStrokeColor(c.lineColor.AsUints())
+	err := c.withAlpha(c.lineColor, func() error {
+		draw()
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("unable to draw line")
+	}
+}
+
+func (c *pdfCanvas) SetLineWidth(width float64) {
+	c.pdf.SetLineWidth(width)
+}
+
+func (c *pdfCanvas) SetLineType(lineType string) {
+	c.pdf.SetLineType(lineType)
+}
+
+func (c *pdfCanvas) SetLineColor(color config.Color) {
+	c.lineColor = color
+}
+
+func (c *pdfCanvas) Line(x1, y1, x2, y2 float64) {
+	c.stroke(func() {
+		c.pdf.Line(x1, y1, x2, y2)
+	})
+}
+
+func (c *pdfCanvas) RectFromUpperLeft(x, y, w, h float64) {
+	c.stroke(func() {
+		c.pdf.RectFromUpperLeft(x, y, w, h)
+	})
+}
+
+func (c *pdfCanvas) FillRect(x, y, w, h float64, color config.Color) {
+	c.pdf.SetFillColor(color.AsUints())
+	err := c.withAlpha(color, func() error {
+		c.pdf.RectFromUpperLeftWithStyle(x, y, w, h, "F")
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("unable to fill rectangle")
+	}
+}
+
+func (c *pdfCanvas) Image(pic *picture, x, y, w, h float64) error {
+	holder, err := gopdf.ImageHolderByBytes(pic.data)
+	if err != nil {
+		return err
+	}
+	return c.pdf.ImageByHolder(holder, x, y, &gopdf.Rect{W: w, H: h})
+}
+
+func (c *pdfCanvas) ClipRect(x, y, w, h float64) {
+	c.pdf.SaveGraphicsState()
+	c.pdf.ClipPolygon([]gopdf.Point{
+		{X: x, Y: y},
+		{X: x + w, Y: y},
+		{X: x + w, Y: y + h},
+		{X: x, Y: y + h},
+	})
+}
+
+func (c *pdfCanvas) ResetClip() {
+	c.pdf.RestoreGraphicsState()
+}
+
+func (c *pdfCanvas) Close() error {
+	if err := c.pdf.Write(c.w); err != nil {
+		return fmt.Errorf("unable to write pdf: %w", err)
+	}
+	return nil
+}
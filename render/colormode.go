@@ -0,0 +1,86 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/nmaupu/gopicto/cache"
+	"github.com/nmaupu/gopicto/config"
+	"image"
+	"image/color"
+	"math"
+)
+
+// ColorMode converts the colors of documents when they are rendered, e.g. to print them in black and white
+type ColorMode struct {
+	// Grayscale converts images and colors to shades of gray
+	Grayscale bool
+	// Brightness is the percentage (-100 to 100) by which images and colors are lightened, darkened if negative
+	Brightness float64
+	// Contrast is the percentage (-100 to 100) by which the contrast of images and colors is increased, decreased if negative
+	Contrast float64
+}
+
+// InkSaver converts documents to grayscale with lightened and less contrasted images and colors to save toner
+var InkSaver = ColorMode{Grayscale: true, Brightness: 30, Contrast: -30}
+
+// IsZero returns true if colors are printed as is
+func (m ColorMode) IsZero() bool {
+	return m == ColorMode{}
+}
+
+func (m ColorMode) validate() error {
+	if m.Brightness < -100 || m.Brightness > 100 {
+		return fmt.Errorf("brightness must be between -100 and 100, got %v", m.Brightness)
+	}
+	if m.Contrast < -100 || m.Contrast > 100 {
+		return fmt.Errorf("contrast must be between -100 and 100, got %v", m.Contrast)
+	}
+	return nil
+}
+
+// color converts a color of the configuration (texts, definitions, cells) the same way as the pixels of images
+func (m ColorMode) color(c config.Color) config.Color {
+	p := m.pixel(color.NRGBA{R: c.R, G: c.G, B: c.B})
+	c.R, c.G, c.B = p.R, p.G, p.B
+	return c
+}
+
+// pixel converts a pixel of an image, brightness being adjusted before contrast
+func (m ColorMode) pixel(c color.NRGBA) color.NRGBA {
+	if m.Grayscale {
+		g := config.Color{R: c.R, G: c.G, B: c.B}.Gray()
+		c.R, c.G, c.B = g.R, g.G, g.B
+	}
+
+	adjust := func(v uint8) uint8 {
+		f := float64(v) + 255*m.Brightness/100
+		f = (f-127.5)*(1+m.Contrast/100) + 127.5
+		return uint8(math.Round(math.Min(255, math.Max(0, f))))
+	}
+	c.R, c.G, c.B = adjust(c.R), adjust(c.G), adjust(c.B)
+	return c
+}
+
+// convertColors returns pic with its colors converted according to opts.colorMode
+func convertColors(pic *picture, opts pictureOptions) (*picture, error) {
+	m := opts.colorMode
+	if m.IsZero() {
+		return pic, nil
+	}
+
+	key := cache.Key("colors", cache.Hash(pic.data), m.Grayscale, m.Brightness, m.Contrast, opts.jpegQuality)
+	converted, err := opts.cached(key, pic.path, func() (*picture, error) {
+		img, format, err := image.Decode(bytes.NewReader(pic.data))
+		if err != nil {
+			return nil, err
+		}
+		return encodePicture(pic.path, imaging.AdjustFunc(img, m.pixel), format, opts.jpegQuality)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Converted SVG images are drawn rasterized
+	converted.svg = nil
+	return converted, nil
+}
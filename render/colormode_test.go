@@ -0,0 +1,33 @@
+package render
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestColorMode_pixel(t *testing.T) {
+	tests := []struct {
+		name string
+		mode ColorMode
+		c    color.NRGBA
+		want color.NRGBA
+	}{
+		{name: "unchanged", mode: ColorMode{}, c: color.NRGBA{R: 10, G: 200, B: 30, A: 128}, want: color.NRGBA{R: 10, G: 200, B: 30, A: 128}},
+		{name: "grayscale", mode: ColorMode{Grayscale: true}, c: color.NRGBA{R: 255, A: 255}, want: color.NRGBA{R: 76, G: 76, B: 76, A: 255}},
+		{name: "lightened", mode: ColorMode{Brightness: 20}, c: color.NRGBA{R: 100, G: 100, B: 100, A: 255}, want: color.NRGBA{R: 151, G: 151, B: 151, A: 255}},
+		{name: "lightened to white", mode: ColorMode{Brightness: 100}, c: color.NRGBA{R: 200, G: 200, B: 200, A: 255}, want: color.NRGBA{R: 255, G: 255, B: 255, A: 255}},
+		{name: "darkened to black", mode: ColorMode{Brightness: -100}, c: color.NRGBA{R: 50, G: 50, B: 50, A: 255}, want: color.NRGBA{A: 255}},
+		{name: "contrast increased", mode: ColorMode{Contrast: 100}, c: color.NRGBA{R: 100, G: 200, B: 127, A: 255}, want: color.NRGBA{R: 73, G: 255, B: 127, A: 255}},
+		{name: "no contrast", mode: ColorMode{Contrast: -100}, c: color.NRGBA{R: 0, G: 255, B: 30, A: 255}, want: color.NRGBA{R: 128, G: 128, B: 128, A: 255}},
+		{name: "brightness before contrast", mode: ColorMode{Brightness: 10, Contrast: 50}, c: color.NRGBA{R: 100, G: 100, B: 100, A: 255}, want: color.NRGBA{R: 125, G: 125, B: 125, A: 255}},
+		{name: "ink saver on black", mode: InkSaver, c: color.NRGBA{A: 255}, want: color.NRGBA{R: 92, G: 92, B: 92, A: 255}},
+		{name: "ink saver on white", mode: InkSaver, c: color.NRGBA{R: 255, G: 255, B: 255, A: 255}, want: color.NRGBA{R: 255, G: 255, B: 255, A: 255}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.mode.pixel(tt.c); got != tt.want {
+				t.Errorf("pixel() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
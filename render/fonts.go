@@ -0,0 +1,141 @@
+package render
+
+import (
+	"fmt"
+	"github.com/nmaupu/gopicto/config"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"io/ioutil"
+	"strings"
+)
+
+// measureDPI is the resolution used to measure text, one pixel being one point at 72 DPI
+const measureDPI = 72
+
+type faceKey struct {
+	family string
+	size   float64
+	dpi    float64
+}
+
+// fontSet loads TTF fonts to measure and draw text on canvases which are not PDF
+type fontSet struct {
+	fonts map[string]*opentype.Font
+	data  map[string][]byte
+	faces map[faceKey]font.Face
+
+	family string
+	size   float64
+}
+
+func newFontSet() *fontSet {
+	return &fontSet{
+		fonts: make(map[string]*opentype.Font),
+		data:  make(map[string][]byte),
+		faces: make(map[faceKey]font.Face),
+	}
+}
+
+// addFont adds a font to c as family, font being the name of a bundled font (see config.FontNames) or the path to a TTF file.
+// Bundled fonts take precedence so that a file in the current directory cannot change their meaning (./name for such a file).
+func addFont(c canvas, family string, font string) error {
+	if data, ok := config.BundledFont(font); ok {
+		return c.AddTTFFontData(family, data)
+	}
+	return c.AddTTFFont(family, font)
+}
+
+func (fs *fontSet) AddTTFFont(family string, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := fs.AddTTFFontData(family, data); err != nil {
+		return fmt.Errorf("unable to parse font %s: %w", path, err)
+	}
+	return nil
+}
+
+func (fs *fontSet) AddTTFFontData(family string, data []byte) error {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return err
+	}
+	fs.fonts[family] = f
+	fs.data[family] = data
+	return nil
+}
+
+func (fs *fontSet) SetFont(family string, size float64) error {
+	if _, ok := fs.fonts[family]; !ok {
+		return fmt.Errorf("font %s has not been added", family)
+	}
+	fs.family = family
+	fs.size = size
+	return nil
+}
+
+// face returns the current font face at the given resolution
+func (fs *fontSet) face(dpi float64) (font.Face, error) {
+	key := faceKey{family: fs.family, size: fs.size, dpi: dpi}
+	if face, ok := fs.faces[key]; ok {
+		return face, nil
+	}
+
+	f, ok := fs.fonts[fs.family]
+	if !ok {
+		return nil, fmt.Errorf("no font selected")
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    fs.size,
+		DPI:     dpi,
+		Hinting: font.HintingNone,
+	})
+	if err != nil {
+		return nil, err
+	}
+	fs.faces[key] = face
+	return face, nil
+}
+
+func (fs *fontSet) MeasureTextWidth(text string) (float64, error) {
+	face, err := fs.face(measureDPI)
+	if err != nil {
+		return 0, err
+	}
+	return float64(font.MeasureString(face, text)) / 64, nil
+}
+
+func (fs *fontSet) SplitTextWithWordWrap(text string, width float64) ([]string, error) {
+	return wrapText(text, width, func(line string, _ int) (float64, error) {
+		return fs.MeasureTextWidth(line)
+	})
+}
+
+// wrapText splits text into lines not wider than width, words wider than width having their own line.
+// measure is given each candidate line and the byte offset it starts at in text.
+func wrapText(text string, width float64, measure func(line string, offset int) (float64, error)) ([]string, error) {
+	lines := make([]string, 0)
+	line, lineOffset, offset := "", 0, 0
+	for _, word := range strings.Split(text, " ") {
+		candidate, candidateOffset := word, offset
+		if line != "" {
+			candidate, candidateOffset = line+" "+word, lineOffset
+		}
+		w, err := measure(candidate, candidateOffset)
+		if err != nil {
+			return nil, err
+		}
+		if w <= width || line == "" {
+			line, lineOffset = candidate, candidateOffset
+		} else {
+			lines = append(lines, line)
+			line, lineOffset = word, offset
+		}
+		offset += len(word) + 1
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
@@ -0,0 +1,45 @@
+package render
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	// Each character is 1pt wide
+	measure := func(text string) func(string, int) (float64, error) {
+		return func(line string, offset int) (float64, error) {
+			if !strings.HasPrefix(text[offset:], line) {
+				return 0, fmt.Errorf("%q is not at offset %d", line, offset)
+			}
+			return float64(len(line)), nil
+		}
+	}
+
+	tests := []struct {
+		name  string
+		text  string
+		width float64
+		want  []string
+	}{
+		{name: "fits on one line", text: "le chat", width: 10, want: []string{"le chat"}},
+		{name: "exact width", text: "le chat", width: 7, want: []string{"le chat"}},
+		{name: "split between words", text: "le chat noir", width: 8, want: []string{"le chat", "noir"}},
+		{name: "one word per line", text: "le chat noir", width: 4, want: []string{"le", "chat", "noir"}},
+		{name: "word wider than width", text: "un hippopotame", width: 5, want: []string{"un", "hippopotame"}},
+		{name: "empty", text: "", width: 5, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wrapText(tt.text, tt.width, measure(tt.text))
+			if err != nil {
+				t.Fatalf("wrapText() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapText() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatPDF  = Format("pdf")
+	FormatPNG  = Format("png")
+	FormatJPEG = Format("jpeg")
+	FormatSVG  = Format("svg")
+
+	DefaultDPI         = 150
+	defaultJPEGQuality = 90
+)
+
+// Format is an output format
+type Format string
+
+// ParseFormat returns the format corresponding to s (e.g. pdf, png, jpg)
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(s, ".")) {
+	case "pdf":
+		return FormatPDF, nil
+	case "png":
+		return FormatPNG, nil
+	case "jpg", "jpeg":
+		return FormatJPEG, nil
+	case "svg":
+		return FormatSVG, nil
+	}
+	return "", fmt.Errorf("unknown format %s (available: pdf, png, jpeg, svg)", s)
+}
+
+// FormatFromFileName infers the format from the extension of a file name, PDF being the default
+func FormatFromFileName(name string) Format {
+	f, err := ParseFormat(filepath.Ext(name))
+	if err != nil {
+		return FormatPDF
+	}
+	return f
+}
+
+// Extension returns the file extension to use for the format
+func (f Format) Extension() string {
+	if f == FormatJPEG {
+		return ".jpg"
+	}
+	return "." + string(f)
+}
+
+// IsPaged returns true if the format produces one file per page
+func (f Format) IsPaged() bool {
+	return f != FormatPDF
+}
@@ -0,0 +1,54 @@
+package render
+
+import "testing"
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Format
+		wantErr bool
+	}{
+		{name: "pdf", s: "pdf", want: FormatPDF},
+		{name: "upper case extension", s: ".PNG", want: FormatPNG},
+		{name: "jpg alias", s: "jpg", want: FormatJPEG},
+		{name: "jpeg", s: "jpeg", want: FormatJPEG},
+		{name: "svg", s: "svg", want: FormatSVG},
+		{name: "unknown", s: "gif", wantErr: true},
+		{name: "empty", s: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFormat() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFromFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want Format
+	}{
+		{name: "pdf", file: "out.pdf", want: FormatPDF},
+		{name: "png", file: "dir/out.png", want: FormatPNG},
+		{name: "jpg", file: "out.JPG", want: FormatJPEG},
+		{name: "svg", file: "out.svg", want: FormatSVG},
+		{name: "no extension", file: "out", want: FormatPDF},
+		{name: "unknown extension", file: "out.gif", want: FormatPDF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatFromFileName(tt.file); got != tt.want {
+				t.Errorf("FormatFromFileName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
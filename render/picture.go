@@ -0,0 +1,328 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/nmaupu/gopicto/cache"
+	"github.com/nmaupu/gopicto/config"
+	"github.com/rs/zerolog/log"
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// vectorDPI is the resolution at which vector images are rasterized when the output is not a raster image
+const vectorDPI = 300
+
+// errUnsupportedFormat is returned when an image cannot be decoded
+var errUnsupportedFormat = fmt.Errorf("unsupported image format (supported: jpeg, png, gif, bmp, tiff, webp, svg)")
+
+// pictureOptions are the settings used to load pictures
+type pictureOptions struct {
+	// w and h are the size in points of the space left to images in cells
+	w, h float64
+	// dpi is the output resolution
+	dpi float64
+	// maxDPI is the maximum resolution of pictures once placed (0 for no limit)
+	maxDPI float64
+	// jpegQuality is the quality of pictures encoded as JPEG
+	jpegQuality int
+	// cache stores processed pictures between runs, nil to disable it
+	cache *cache.Cache
+	// colorMode converts the colors of pictures
+	colorMode ColorMode
+}
+
+// picture is an image ready to be drawn, once oriented and transformed
+type picture struct {
+	// path is the file the picture has been loaded from
+	path string
+	// data is the encoded picture, the content of the original file if it has not been modified
+	data []byte
+	// modified is true if data differs from the original file
+	modified bool
+	// w and h are the dimensions in pixels
+	w, h float64
+	// svg is the original SVG image when it can be drawn as is by vector outputs
+	svg []byte
+	// downsampledFrom is the size in bytes of the picture before being downsampled, 0 if it has not been
+	downsampledFrom int
+}
+
+// loadPicture reads the image of iw, honouring its EXIF orientation and applying its transforms
+func loadPicture(iw config.ImageWord, opts pictureOptions) (*picture, error) {
+	data, err := ioutil.ReadFile(iw.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	if isSVG(iw.Image) {
+		key := cache.Key("svg", cache.Hash(data), transformsKey(iw), opts.w, opts.h, opts.dpi)
+		pic, err := opts.cached(key, iw.Image, func() (*picture, error) {
+			return loadSVGPicture(iw, data, opts)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !iw.HasTransforms() {
+			pic.svg = data
+		}
+		return pic, nil
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err == image.ErrFormat {
+		return nil, errUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Images which are not JPEG or PNG are transcoded so that they can be embedded in PDF (only the first frame of GIF)
+	orientation := exifOrientation(data)
+	if orientation <= 1 && !iw.HasTransforms() && (format == "jpeg" || format == "png") {
+		return &picture{path: iw.Image, data: data, w: float64(cfg.Width), h: float64(cfg.Height)}, nil
+	}
+
+	key := cache.Key("picture", cache.Hash(data), transformsKey(iw), opts.jpegQuality)
+	return opts.cached(key, iw.Image, func() (*picture, error) {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		img = orient(img, orientation)
+		img, err = transform(img, iw, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return encodePicture(iw.Image, img, format, opts.jpegQuality)
+	})
+}
+
+// transformsKey returns the part of a cache key identifying the transforms of iw
+func transformsKey(iw config.ImageWord) string {
+	crop := "none"
+	if iw.Crop != nil {
+		crop = fmt.Sprintf("%+v", *iw.Crop)
+	}
+	return fmt.Sprintf("crop=%s rotate=%d flipH=%t flipV=%t", crop, iw.Rotate, iw.FlipH, iw.FlipV)
+}
+
+// cached returns the picture stored in the cache under key if any.
+// Otherwise, the picture is processed and stored in the cache.
+func (opts pictureOptions) cached(key, path string, process func() (*picture, error)) (*picture, error) {
+	if opts.cache == nil {
+		return process()
+	}
+
+	if data, ok := opts.cache.Get(key); ok {
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err == nil {
+			return &picture{path: path, data: data, modified: true, w: float64(cfg.Width), h: float64(cfg.Height)}, nil
+		}
+		log.Warn().Err(err).Str("image", path).Msg("Ignoring invalid cache entry")
+	}
+
+	pic, err := process()
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.cache.Put(key, pic.data); err != nil {
+		log.Warn().Err(err).Str("image", path).Msg("Unable to store image in cache")
+	}
+	return pic, nil
+}
+
+// encodePicture returns a modified picture made of img, encoded as JPEG if format is jpeg, as PNG otherwise
+func encodePicture(path string, img image.Image, format string, jpegQuality int) (*picture, error) {
+	buf := &bytes.Buffer{}
+	var err error
+	if format == "jpeg" {
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(buf, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode image: %w", err)
+	}
+
+	b := img.Bounds()
+	return &picture{
+		path:     path,
+		data:     buf.Bytes(),
+		modified: true,
+		w:        float64(b.Dx()),
+		h:        float64(b.Dy()),
+	}, nil
+}
+
+func isSVG(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".svg")
+}
+
+// loadSVGPicture rasterizes an SVG image with a resolution high enough for it to cover the space left to images
+func loadSVGPicture(iw config.ImageWord, data []byte, opts pictureOptions) (*picture, error) {
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(data), oksvg.IgnoreErrorMode)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse svg: %w", err)
+	}
+
+	// Crop is expressed in SVG units
+	svgW, svgH := icon.ViewBox.W, icon.ViewBox.H
+	if iw.Crop != nil {
+		svgW, svgH = float64(iw.Crop.W), float64(iw.Crop.H)
+	}
+	if svgW <= 0 || svgH <= 0 {
+		return nil, fmt.Errorf("svg has no size (missing viewBox, width or height)")
+	}
+	if iw.Rotate%180 != 0 {
+		svgW, svgH = svgH, svgW
+	}
+
+	// Pixels per SVG unit
+	scale := math.Max(opts.w/svgW, opts.h/svgH) * opts.dpi / 72
+	w := int(math.Ceil(icon.ViewBox.W * scale))
+	h := int(math.Ceil(icon.ViewBox.H * scale))
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("svg is too small to be rasterized")
+	}
+
+	icon.SetTarget(0, 0, float64(w), float64(h))
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	scanner := rasterx.NewScannerGV(w, h, img, img.Bounds())
+	icon.Draw(rasterx.NewDasher(w, h, scanner), 1)
+
+	transformed, err := transform(img, iw, scale)
+	if err != nil {
+		return nil, err
+	}
+	return encodePicture(iw.Image, transformed, "png", opts.jpegQuality)
+}
+
+// downsample returns pic resampled so that its resolution does not exceed opts.maxDPI once placed on w x h points.
+// pic is returned as is if its resolution is low enough or if resampling does not make it smaller.
+func downsample(pic *picture, w, h float64, opts pictureOptions) (*picture, error) {
+	if opts.maxDPI <= 0 {
+		return pic, nil
+	}
+	maxW := int(math.Ceil(w / 72 * opts.maxDPI))
+	maxH := int(math.Ceil(h / 72 * opts.maxDPI))
+	if pic.w <= float64(maxW) && pic.h <= float64(maxH) {
+		return pic, nil
+	}
+
+	key := cache.Key("downsample", cache.Hash(pic.data), maxW, maxH, opts.jpegQuality)
+	resized, err := opts.cached(key, pic.path, func() (*picture, error) {
+		img, err := pic.decode()
+		if err != nil {
+			return nil, err
+		}
+
+		// Images without transparency are encoded as JPEG as it is way smaller than PNG for photos
+		format := "png"
+		if o, ok := img.(interface{ Opaque() bool }); ok && o.Opaque() {
+			format = "jpeg"
+		}
+		return encodePicture(pic.path, imaging.Resize(img, maxW, maxH, imaging.Lanczos), format, opts.jpegQuality)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resized.data) >= len(pic.data) {
+		return pic, nil
+	}
+	resized.svg = pic.svg
+	resized.downsampledFrom = len(pic.data)
+	return resized, nil
+}
+
+// decode decodes the pixels of the picture
+func (p *picture) decode() (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(p.data))
+	return img, err
+}
+
+// exifOrientation returns the EXIF orientation tag of an image, 0 if there is none
+func exifOrientation(data []byte) int {
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0
+	}
+	tag, err := x.Get(exif.Orientation)
+	if err != nil {
+		return 0
+	}
+	o, err := tag.Int(0)
+	if err != nil {
+		return 0
+	}
+	return o
+}
+
+// orient rotates and flips img so that it is displayed upright according to its EXIF orientation
+func orient(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	}
+	return img
+}
+
+// transform crops, rotates (clockwise) and flips img as configured in iw,
+// crop being multiplied by scale to get pixels
+func transform(img image.Image, iw config.ImageWord, scale float64) (image.Image, error) {
+	if iw.Crop != nil {
+		px := func(v int) int { return int(math.Round(float64(v) * scale)) }
+		r := image.Rect(px(iw.Crop.X), px(iw.Crop.Y), px(iw.Crop.X+iw.Crop.W), px(iw.Crop.Y+iw.Crop.H))
+		b := img.Bounds()
+		if !r.Add(b.Min).In(b) {
+			return nil, fmt.Errorf("crop %dx%d+%d+%d is out of the image (%dx%d)",
+				iw.Crop.W, iw.Crop.H, iw.Crop.X, iw.Crop.Y, int(math.Round(float64(b.Dx())/scale)), int(math.Round(float64(b.Dy())/scale)))
+		}
+		img = imaging.Crop(img, r.Add(b.Min))
+	}
+
+	// imaging rotates counter-clockwise
+	switch (iw.Rotate%360 + 360) % 360 {
+	case 90:
+		img = imaging.Rotate270(img)
+	case 180:
+		img = imaging.Rotate180(img)
+	case 270:
+		img = imaging.Rotate90(img)
+	}
+
+	if iw.FlipH {
+		img = imaging.FlipH(img)
+	}
+	if iw.FlipV {
+		img = imaging.FlipV(img)
+	}
+	return img, nil
+}
@@ -0,0 +1,149 @@
+package render
+
+import (
+	"github.com/nmaupu/gopicto/config"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// markedImage returns a w x h image with a red pixel at (0, 0)
+func markedImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	return img
+}
+
+// markPosition returns the position of the red pixel of img relative to its bounds
+func markPosition(img image.Image) image.Point {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r != 0 {
+				return image.Pt(x-b.Min.X, y-b.Min.Y)
+			}
+		}
+	}
+	return image.Pt(-1, -1)
+}
+
+func TestOrient(t *testing.T) {
+	tests := []struct {
+		name        string
+		orientation int
+		wantSize    image.Point
+		wantMark    image.Point
+	}{
+		{name: "no orientation", orientation: 0, wantSize: image.Pt(3, 2), wantMark: image.Pt(0, 0)},
+		{name: "upright", orientation: 1, wantSize: image.Pt(3, 2), wantMark: image.Pt(0, 0)},
+		{name: "mirrored", orientation: 2, wantSize: image.Pt(3, 2), wantMark: image.Pt(2, 0)},
+		{name: "upside down", orientation: 3, wantSize: image.Pt(3, 2), wantMark: image.Pt(2, 1)},
+		{name: "upside down mirrored", orientation: 4, wantSize: image.Pt(3, 2), wantMark: image.Pt(0, 1)},
+		{name: "transposed", orientation: 5, wantSize: image.Pt(2, 3), wantMark: image.Pt(0, 0)},
+		{name: "rotated clockwise", orientation: 6, wantSize: image.Pt(2, 3), wantMark: image.Pt(1, 0)},
+		{name: "transversed", orientation: 7, wantSize: image.Pt(2, 3), wantMark: image.Pt(1, 2)},
+		{name: "rotated counter-clockwise", orientation: 8, wantSize: image.Pt(2, 3), wantMark: image.Pt(0, 2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := orient(markedImage(3, 2), tt.orientation)
+			if size := got.Bounds().Size(); size != tt.wantSize {
+				t.Errorf("orient() size = %v, want %v", size, tt.wantSize)
+			}
+			if mark := markPosition(got); mark != tt.wantMark {
+				t.Errorf("orient() mark = %v, want %v", mark, tt.wantMark)
+			}
+		})
+	}
+}
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name     string
+		iw       config.ImageWord
+		scale    float64 // 1 if not set
+		wantSize image.Point
+		wantMark image.Point
+		wantErr  bool
+	}{
+		{name: "none", iw: config.ImageWord{}, wantSize: image.Pt(3, 2), wantMark: image.Pt(0, 0)},
+		{name: "crop keeping the mark", iw: config.ImageWord{Crop: &config.Crop{W: 2, H: 1}}, wantSize: image.Pt(2, 1), wantMark: image.Pt(0, 0)},
+		{name: "crop removing the mark", iw: config.ImageWord{Crop: &config.Crop{X: 1, W: 2, H: 2}}, wantSize: image.Pt(2, 2), wantMark: image.Pt(-1, -1)},
+		{name: "crop out of the image", iw: config.ImageWord{Crop: &config.Crop{X: 2, W: 2, H: 2}}, wantErr: true},
+		{name: "crop scaled", iw: config.ImageWord{Crop: &config.Crop{W: 1, H: 1}}, scale: 2, wantSize: image.Pt(2, 2), wantMark: image.Pt(0, 0)},
+		{name: "crop scaled out of the image", iw: config.ImageWord{Crop: &config.Crop{W: 2, H: 2}}, scale: 2, wantErr: true},
+		{name: "rotate 90", iw: config.ImageWord{Rotate: 90}, wantSize: image.Pt(2, 3), wantMark: image.Pt(1, 0)},
+		{name: "rotate 180", iw: config.ImageWord{Rotate: 180}, wantSize: image.Pt(3, 2), wantMark: image.Pt(2, 1)},
+		{name: "rotate -90", iw: config.ImageWord{Rotate: -90}, wantSize: image.Pt(2, 3), wantMark: image.Pt(0, 2)},
+		{name: "rotate 450", iw: config.ImageWord{Rotate: 450}, wantSize: image.Pt(2, 3), wantMark: image.Pt(1, 0)},
+		{name: "flip horizontally", iw: config.ImageWord{FlipH: true}, wantSize: image.Pt(3, 2), wantMark: image.Pt(2, 0)},
+		{name: "flip vertically", iw: config.ImageWord{FlipV: true}, wantSize: image.Pt(3, 2), wantMark: image.Pt(0, 1)},
+		{name: "rotate then flip", iw: config.ImageWord{Rotate: 90, FlipH: true}, wantSize: image.Pt(2, 3), wantMark: image.Pt(0, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scale := tt.scale
+			if scale == 0 {
+				scale = 1
+			}
+			got, err := transform(markedImage(3, 2), tt.iw, scale)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("transform() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if size := got.Bounds().Size(); size != tt.wantSize {
+				t.Errorf("transform() size = %v, want %v", size, tt.wantSize)
+			}
+			if mark := markPosition(got); mark != tt.wantMark {
+				t.Errorf("transform() mark = %v, want %v", mark, tt.wantMark)
+			}
+		})
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	// Noise so that the resized image is smaller than the original one
+	img := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	rnd := rand.New(rand.NewSource(1))
+	for i := range img.Pix {
+		img.Pix[i] = byte(rnd.Intn(256))
+		if i%4 == 3 {
+			img.Pix[i] = 255
+		}
+	}
+	pic, err := encodePicture("noise.png", img, "png", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		w, h          float64
+		maxDPI        float64
+		wantW, wantH  float64
+		wantUnchanged bool
+	}{
+		{name: "no limit", w: 72, h: 36, maxDPI: 0, wantW: 400, wantH: 200, wantUnchanged: true},
+		{name: "under the limit", w: 72, h: 36, maxDPI: 600, wantW: 400, wantH: 200, wantUnchanged: true},
+		{name: "exactly the limit", w: 72, h: 36, maxDPI: 400, wantW: 400, wantH: 200, wantUnchanged: true},
+		{name: "above the limit", w: 72, h: 36, maxDPI: 100, wantW: 100, wantH: 50},
+		{name: "rounded up", w: 50, h: 25, maxDPI: 100, wantW: 70, wantH: 35},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := downsample(pic, tt.w, tt.h, pictureOptions{maxDPI: tt.maxDPI, jpegQuality: 90})
+			if err != nil {
+				t.Fatalf("downsample() error = %v", err)
+			}
+			if (got == pic) != tt.wantUnchanged {
+				t.Errorf("downsample() unchanged = %v, want %v", got == pic, tt.wantUnchanged)
+			}
+			if got.w != tt.wantW || got.h != tt.wantH {
+				t.Errorf("downsample() size = %vx%v, want %vx%v", got.w, got.h, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"github.com/nmaupu/gopicto/cache"
+	"github.com/nmaupu/gopicto/config"
+	"github.com/nmaupu/gopicto/draw"
+	"github.com/rs/zerolog/log"
+	"runtime"
+	"sync"
+)
+
+// preparePictures loads and preprocesses the images of all cells concurrently using a pool of workers.
+// Pictures are stored in the order of cfg.ImageWords so that documents do not depend on the order they have been processed in.
+// An image used by several cells with the same transforms and fit mode is only prepared once.
+func (g *generator) preparePictures(ctx context.Context) error {
+	g.picOpts = newPictureOptions(g.cfg, g.opts)
+	iws := g.cfg.ImageWords
+	g.pictures = make([]*picture, len(iws))
+	errs := make([]error, len(iws))
+
+	// first is the index of the first image word of each key, the only one being prepared
+	first := make(map[string]int)
+	unique := make([]int, 0, len(iws))
+	for i, iw := range iws {
+		key := pictureKey(iw)
+		if _, ok := first[key]; !ok {
+			first[key] = i
+			unique = append(unique, i)
+		}
+	}
+
+	workers := g.opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// Remaining images are not prepared once one has failed
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				g.pictures[i], errs[i] = g.preparePicture(iws[i])
+				if errs[i] != nil {
+					cancel()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, i := range unique {
+		select {
+		case <-workCtx.Done():
+			break feed
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// Reporting the error of the first image in order, whatever the one which has failed first
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, iw := range iws {
+		g.pictures[i] = g.pictures[first[pictureKey(iw)]]
+	}
+
+	g.logDownsampling()
+	return nil
+}
+
+// pictureKey identifies the pictures prepared the same way
+func pictureKey(iw config.ImageWord) string {
+	return fmt.Sprintf("%s %s fit=%s", iw.Image, transformsKey(iw), iw.Fit)
+}
+
+// preparePicture loads the image of iw, converts its colors and downsamples it to the size it is placed at
+func (g *generator) preparePicture(iw config.ImageWord) (*picture, error) {
+	pic, err := loadPicture(iw, g.picOpts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read image %s: %w", iw.Image, err)
+	}
+
+	pic, err = convertColors(pic, g.picOpts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert colors of image %s: %w", iw.Image, err)
+	}
+
+	// All cells have the same size
+	c := draw.NewPictoCell(g.cfg.Page.Margins, 0, 0, g.cellW, g.cellH, iw)
+	w, h := g.imageSize(c, pic.w, pic.h)
+	placed, err := downsample(pic, w, h, g.picOpts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to downsample image %s: %w", iw.Image, err)
+	}
+	return placed, nil
+}
+
+// logDownsampling reports the size saved by downsampling pictures
+func (g *generator) logDownsampling() {
+	count, before, after := 0, 0, 0
+	seen := make(map[*picture]bool)
+	for _, pic := range g.pictures {
+		// Pictures shared by several cells are only counted once
+		if pic.downsampledFrom == 0 || seen[pic] {
+			continue
+		}
+		seen[pic] = true
+		count++
+		before += pic.downsampledFrom
+		after += len(pic.data)
+	}
+	if count == 0 {
+		return
+	}
+
+	log.Info().
+		Int("images", count).
+		Str("before", cache.HumanSize(int64(before))).
+		Str("after", cache.HumanSize(int64(after))).
+		Str("saved", fmt.Sprintf("%.1f%%", 100*float64(before-after)/float64(before))).
+		Msg("Images downsampled")
+}
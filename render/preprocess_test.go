@@ -0,0 +1,73 @@
+package render
+
+import (
+	"context"
+	"github.com/nmaupu/gopicto/config"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreparePictures(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "chat.png")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewNRGBA(image.Rect(0, 0, 4, 2))); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	prepare := func(iws ...config.ImageWord) *generator {
+		cfg := config.PDF{Page: config.Page{Cols: 2, Lines: 2}, ImageWords: iws}
+		if err := cfg.Init(); err != nil {
+			t.Fatal(err)
+		}
+		return &generator{cfg: cfg, opts: Options{Workers: 2}, cellW: 100, cellH: 100}
+	}
+
+	t.Run("same image prepared once", func(t *testing.T) {
+		g := prepare(
+			config.ImageWord{Image: file, Text: "chat"},
+			config.ImageWord{Image: file, Text: "chat", Rotate: 90},
+			config.ImageWord{Image: file, Text: "chat"},
+		)
+		if err := g.preparePictures(context.Background()); err != nil {
+			t.Fatalf("preparePictures() error = %v", err)
+		}
+		if g.pictures[0] != g.pictures[2] {
+			t.Errorf("preparePictures() the same image should be shared")
+		}
+		if g.pictures[0] == g.pictures[1] || g.pictures[1].w != 2 {
+			t.Errorf("preparePictures() images with different transforms should be prepared separately")
+		}
+	})
+
+	t.Run("first error in order", func(t *testing.T) {
+		g := prepare(
+			config.ImageWord{Image: file, Text: "chat"},
+			config.ImageWord{Image: filepath.Join(dir, "a.png"), Text: "a"},
+			config.ImageWord{Image: filepath.Join(dir, "b.png"), Text: "b"},
+		)
+		err := g.preparePictures(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "a.png") {
+			t.Errorf("preparePictures() error = %v, want the one of a.png", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		g := prepare(config.ImageWord{Image: file, Text: "chat"})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := g.preparePictures(ctx); err != context.Canceled {
+			t.Errorf("preparePictures() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package render
+
+import (
+	"fmt"
+	"github.com/nmaupu/gopicto/config"
+	"github.com/rs/zerolog/log"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+)
+
+// dottedLinePattern is the length in points of dashes and gaps of dotted lines
+const dottedLinePattern = 2
+
+// rasterCanvas draws pages as images and writes each one of them when the next page is added
+type rasterCanvas struct {
+	*fontSet
+	format  Format
+	dpi     float64
+	scale   float64 // pixels per point
+	pageW   float64
+	pageH   float64
+	newPage PageWriterFunc
+
+	img       *image.RGBA
+	clip      *image.Rectangle
+	page      int
+	x, y      float64
+	lineWidth float64
+	lineType  string
+	lineColor config.Color
+}
+
+func newRasterCanvas(pageW, pageH float64, format Format, dpi float64, newPage PageWriterFunc) *rasterCanvas {
+	return &rasterCanvas{
+		fontSet:   newFontSet(),
+		format:    format,
+		dpi:       dpi,
+		scale:     dpi / 72,
+		pageW:     pageW,
+		pageH:     pageH,
+		newPage:   newPage,
+		lineWidth: 1,
+	}
+}
+
+func (c *rasterCanvas) AddPage() error {
+	if err := c.flush(); err != nil {
+		return err
+	}
+
+	c.page++
+	c.clip = nil
+	c.img = image.NewRGBA(image.Rect(0, 0, c.px(c.pageW), c.px(c.pageH)))
+	xdraw.Draw(c.img, c.img.Bounds(), image.White, image.Point{}, xdraw.Src)
+	return nil
+}
+
+// flush writes the current page if any
+func (c *rasterCanvas) flush() error {
+	if c.img == nil {
+		return nil
+	}
+
+	w, err := c.newPage(c.page)
+	if err != nil {
+		return fmt.Errorf("unable to create page %d: %w", c.page, err)
+	}
+	defer func() {
+		if err := w.Close(); err != nil {
+			log.Error().Err(err).Msg("unable to close page")
+		}
+	}()
+
+	switch c.format {
+	case FormatJPEG:
+		err = jpeg.Encode(w, c.img, &jpeg.Options{Quality: defaultJPEGQuality})
+	default:
+		err = png.Encode(w, c.img)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to encode page %d: %w", c.page, err)
+	}
+
+	c.img = nil
+	return nil
+}
+
+func (c *rasterCanvas) Close() error {
+	return c.flush()
+}
+
+// px converts points to pixels
+func (c *rasterCanvas) px(v float64) int {
+	return int(math.Round(v * c.scale))
+}
+
+// dst returns the part of the page which can be drawn on
+func (c *rasterCanvas) dst() *image.RGBA {
+	if c.clip == nil {
+		return c.img
+	}
+	return c.img.SubImage(*c.clip).(*image.RGBA)
+}
+
+func (c *rasterCanvas) ClipRect(x, y, w, h float64) {
+	r := image.Rect(c.px(x), c.px(y), c.px(x+w), c.px(y+h))
+	c.clip = &r
+}
+
+func (c *rasterCanvas) ResetClip() {
+	c.clip = nil
+}
+
+func (c *rasterCanvas) SetXY(x, y float64) {
+	c.x, c.y = x, y
+}
+
+func (c *rasterCanvas) Text(text string, col config.Color) error {
+	face, err := c.face(c.dpi)
+	if err != nil {
+		return err
+	}
+	d := font.Drawer{
+		Dst:  c.dst(),
+		Src:  image.NewUniform(nrgba(col)),
+		Face: face,
+		Dot:  fixed.Point26_6{X: fixed.Int26_6(c.x * c.scale * 64), Y: fixed.Int26_6(c.y * c.scale * 64)},
+	}
+	d.DrawString(text)
+
+	w, err := c.MeasureTextWidth(text)
+	if err != nil {
+		return err
+	}
+	c.x += w
+	return nil
+}
+
+func (c *rasterCanvas) SetLineWidth(width float64) {
+	c.lineWidth = width
+}
+
+func (c *rasterCanvas) SetLineType(lineType string) {
+	c.lineType = lineType
+}
+
+func (c *rasterCanvas) SetLineColor(col config.Color) {
+	c.lineColor = col
+}
+
+// nrgba converts a color of the configuration
+func nrgba(col config.Color) color.NRGBA {
+	return color.NRGBA{R: col.R, G: col.G, B: col.B, A: col.Alpha()}
+}
+
+// stroke draws lines with the current line color.
+// Lines are drawn on a mask first so that overlapping parts of transparent lines are not darker.
+func (c *rasterCanvas) stroke(lines ...[4]float64) {
+	// Mask covering the lines only
+	var bounds image.Rectangle
+	margin := int(math.Ceil(c.lineWidth*c.scale/2)) + 1
+	for _, l := range lines {
+		r := image.Rect(c.px(l[0]), c.px(l[1]), c.px(l[2]), c.px(l[3])).Inset(-margin)
+		bounds = bounds.Union(r)
+	}
+	mask := image.NewAlpha(bounds.Intersect(c.img.Bounds()))
+	for _, l := range lines {
+		c.strokeMask(mask, l[0], l[1], l[2], l[3])
+	}
+
+	dst := c.dst()
+	xdraw.DrawMask(dst, mask.Bounds(), image.NewUniform(nrgba(c.lineColor)), image.Point{}, mask, mask.Bounds().Min, xdraw.Over)
+}
+
+func (c *rasterCanvas) Line(x1, y1, x2, y2 float64) {
+	c.stroke([4]float64{x1, y1, x2, y2})
+}
+
+// strokeMask draws a line made of squares as wide as the line on mask, skipping gaps of dotted lines
+func (c *rasterCanvas) strokeMask(mask *image.Alpha, x1, y1, x2, y2 float64) {
+	length := math.Hypot(x2-x1, y2-y1)
+	half := math.Max(c.lineWidth*c.scale/2, .5)
+	step := .5 / c.scale // half a pixel
+	for d := 0.0; d <= length; d += step {
+		if c.lineType == "dotted" && int(d/dottedLinePattern)%2 == 1 {
+			continue
+		}
+		ratio := 0.0
+		if length > 0 {
+			ratio = d / length
+		}
+		x := (x1 + (x2-x1)*ratio) * c.scale
+		y := (y1 + (y2-y1)*ratio) * c.scale
+		r := image.Rect(int(math.Round(x-half)), int(math.Round(y-half)), int(math.Round(x+half)), int(math.Round(y+half)))
+		xdraw.Draw(mask, r, image.Opaque, image.Point{}, xdraw.Src)
+	}
+}
+
+func (c *rasterCanvas) RectFromUpperLeft(x, y, w, h float64) {
+	c.stroke(
+		[4]float64{x, y, x + w, y},
+		[4]float64{x + w, y, x + w, y + h},
+		[4]float64{x + w, y + h, x, y + h},
+		[4]float64{x, y + h, x, y},
+	)
+}
+
+func (c *rasterCanvas) FillRect(x, y, w, h float64, col config.Color) {
+	r := image.Rect(c.px(x), c.px(y), c.px(x+w), c.px(y+h))
+	xdraw.Draw(c.dst(), r, image.NewUniform(nrgba(col)), image.Point{}, xdraw.Over)
+}
+
+func (c *rasterCanvas) Image(pic *picture, x, y, w, h float64) error {
+	src, err := pic.decode()
+	if err != nil {
+		return err
+	}
+
+	dst := image.Rect(c.px(x), c.px(y), c.px(x+w), c.px(y+h))
+	xdraw.CatmullRom.Scale(c.dst(), dst, src, src.Bounds(), xdraw.Over, nil)
+	return nil
+}
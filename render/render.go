@@ -0,0 +1,770 @@
+// Package render generates picto/word documents (PDF, or one image or SVG per page) from a configuration
+package render
+
+import (
+	"context"
+	"fmt"
+	"github.com/nmaupu/gopdf"
+	"github.com/nmaupu/gopicto/cache"
+	"github.com/nmaupu/gopicto/config"
+	"github.com/nmaupu/gopicto/draw"
+	"github.com/rs/zerolog/log"
+	"io"
+	"math"
+	"path"
+	"strings"
+)
+
+type pageMode string
+type cellPrinter func(g *generator, c draw.PictoCell, idx int) error
+
+// pictoText is the layout of the text of a picto
+type pictoText struct {
+	size  float64
+	lines []string
+}
+
+const (
+	fontFamilyNameText        = "fontText"
+	fontFamilyNameDefinitions = "fontDefinitions"
+	pageModePictos            = "pictos"
+	pageModeDefinitions       = "definitions"
+
+	// pictoLineSpacingRatio is the line spacing of picto texts wrapped onto two lines
+	pictoLineSpacingRatio = .15
+)
+
+// Options are the rendering options which are not part of the configuration file
+type Options struct {
+	// CutLines draws cut lines around cells
+	CutLines bool
+	// Format is the format of the pages generated by GeneratePages
+	Format Format
+	// DPI is the resolution of raster pages, DefaultDPI if not provided
+	DPI float64
+	// LinkImages links image files from SVG pages instead of embedding them
+	LinkImages bool
+	// Cache stores processed images so that they are reused between runs, nil to disable it
+	Cache *cache.Cache
+	// Workers is the number of images preprocessed concurrently, the number of CPUs if not provided
+	Workers int
+	// ColorMode converts images and colors, e.g. to grayscale
+	ColorMode ColorMode
+}
+
+// PageWriterFunc returns the writer to use for the n-th page (starting at 1) when generating one file per page
+type PageWriterFunc func(n int) (io.WriteCloser, error)
+
+// generator holds the state needed to render a document
+type generator struct {
+	canvas          canvas
+	cfg             config.PDF
+	opts            Options
+	pageW           float64
+	pageH           float64
+	cellW           float64
+	cellH           float64
+	haveDefinitions bool
+	// texts and pictures are the layout of picto texts and the preprocessed images, indexed like cfg.ImageWords
+	texts    []pictoText
+	pictures []*picture
+	picOpts  pictureOptions
+	// variants are the families of the font variants which have been added (e.g. fontTextBold)
+	variants map[string]bool
+}
+
+// Generate renders cfg as a PDF and writes it to w.
+// cfg is expected to be initialized (see config.PDF.Init).
+func Generate(ctx context.Context, cfg config.PDF, opts Options, w io.Writer) error {
+	g, err := newGenerator(cfg, opts, func(pageW, pageH float64) canvas {
+		return newPdfCanvas(gopdf.Rect{W: pageW, H: pageH}, w)
+	})
+	if err != nil {
+		return err
+	}
+
+	return g.run(ctx)
+}
+
+// GeneratePages renders each page of cfg as a separate file using opts.Format,
+// newPage being called to get the writer of each page.
+// cfg is expected to be initialized (see config.PDF.Init).
+func GeneratePages(ctx context.Context, cfg config.PDF, opts Options, newPage PageWriterFunc) error {
+	if opts.DPI <= 0 {
+		opts.DPI = DefaultDPI
+	}
+
+	var newCanvas func(pageW, pageH float64) canvas
+	switch opts.Format {
+	case FormatPNG, FormatJPEG:
+		newCanvas = func(pageW, pageH float64) canvas {
+			return newRasterCanvas(pageW, pageH, opts.Format, opts.DPI, newPage)
+		}
+	case FormatSVG:
+		newCanvas = func(pageW, pageH float64) canvas {
+			return newSvgCanvas(pageW, pageH, opts.LinkImages, newPage)
+		}
+	default:
+		return fmt.Errorf("format %s cannot be generated page by page", opts.Format)
+	}
+
+	g, err := newGenerator(cfg, opts, newCanvas)
+	if err != nil {
+		return err
+	}
+
+	return g.run(ctx)
+}
+
+// run prints all the pages and closes the canvas
+func (g *generator) run(ctx context.Context) error {
+	texts, err := g.pictoTexts()
+	if err != nil {
+		return err
+	}
+	g.texts = texts
+
+	if err := g.preparePictures(ctx); err != nil {
+		return err
+	}
+
+	nbPictoPages := g.cfg.GetNbPictoPages()
+	for page := 0; page < nbPictoPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := g.printPage(page, pageModePictos); err != nil {
+			return fmt.Errorf("unable to print page %d: %w", page+1, err)
+		}
+		if g.haveDefinitions {
+			if err := g.printPage(page, pageModeDefinitions); err != nil {
+				return fmt.Errorf("unable to print definitions of page %d: %w", page+1, err)
+			}
+		}
+	}
+
+	return g.canvas.Close()
+}
+
+// newGenerator creates the canvas to draw on and registers the fonts needed by cfg
+func newGenerator(cfg config.PDF, opts Options, newCanvas func(pageW, pageH float64) canvas) (*generator, error) {
+	if err := opts.ColorMode.validate(); err != nil {
+		return nil, err
+	}
+
+	pageW, pageH := cfg.Page.Dimensions()
+	g := &generator{
+		canvas:   newCanvas(pageW, pageH),
+		cfg:      cfg,
+		opts:     opts,
+		pageW:    pageW,
+		pageH:    pageH,
+		variants: make(map[string]bool),
+	}
+	g.cellW = (pageW - cfg.Page.PageMargins.LeftRight()) / float64(cfg.Page.Cols)
+	g.cellH = (pageH - cfg.Page.PageMargins.TopBottom()) / float64(cfg.Page.Lines)
+
+	// Even page contains definitions ?
+	for _, iw := range cfg.ImageWords {
+		if iw.Def.Text != "" {
+			g.haveDefinitions = true
+			break
+		}
+	}
+
+	textFont := cfg.Text.Font
+	if textFont == "" {
+		log.Info().Msgf("Config text.font is not provided, using default font %s", config.DefaultFont)
+		textFont = config.DefaultFont
+	}
+
+	if err := addFont(g.canvas, fontFamilyNameText, textFont); err != nil {
+		return nil, fmt.Errorf("unable to use font %s: %w", textFont, err)
+	}
+	if err := g.addFontVariants(fontFamilyNameText, cfg.Text.FontVariants); err != nil {
+		return nil, err
+	}
+
+	if g.haveDefinitions {
+		defFont := cfg.Text.Definitions.Font
+		defVariants := cfg.Text.Definitions.FontVariants
+		if defFont == "" {
+			defFont = textFont
+			// Definitions also use the variants of the text font unless their own are provided
+			if defVariants == (config.FontVariants{}) {
+				defVariants = cfg.Text.FontVariants
+			}
+		}
+		if err := addFont(g.canvas, fontFamilyNameDefinitions, defFont); err != nil {
+			return nil, fmt.Errorf("unable to use font %s: %w", defFont, err)
+		}
+		if err := g.addFontVariants(fontFamilyNameDefinitions, defVariants); err != nil {
+			return nil, err
+		}
+	}
+	g.warnMissingFontVariants()
+
+	return g, nil
+}
+
+// newPictureOptions returns the settings used to load the pictures of cfg
+func newPictureOptions(cfg config.PDF, opts Options) pictureOptions {
+	pageW, pageH := cfg.Page.Dimensions()
+	cellW := (pageW-cfg.Page.PageMargins.LeftRight())/float64(cfg.Page.Cols) - cfg.Page.Margins.LeftRight()
+	cellH := (pageH-cfg.Page.PageMargins.TopBottom())/float64(cfg.Page.Lines) - cfg.Page.Margins.TopBottom()
+
+	dpi := float64(vectorDPI)
+	if opts.Format == FormatPNG || opts.Format == FormatJPEG {
+		dpi = opts.DPI
+	}
+
+	return pictureOptions{
+		w:           cellW - cfg.Page.Paddings.LeftRight(),
+		h:           cellH - cellH*cfg.Text.Ratio - cfg.Page.Paddings.TopBottom(),
+		dpi:         dpi,
+		maxDPI:      cfg.Output.MaxDPI,
+		jpegQuality: cfg.Output.JPEGQuality,
+		cache:       opts.Cache,
+		colorMode:   opts.ColorMode,
+	}
+}
+
+// pictoTexts returns the layout of the text of each picto, depending on the sizing mode
+func (g *generator) pictoTexts() ([]pictoText, error) {
+	cfg := g.cfg
+	texts := make([]pictoText, len(cfg.ImageWords))
+	for i, iw := range cfg.ImageWords {
+		texts[i] = pictoText{size: cfg.Text.FontSize, lines: strings.Split(iw.Text, "\n")}
+	}
+
+	if cfg.Text.Sizing == config.SizingPerCell {
+		for i := range texts {
+			t, err := g.fitPictoText(cfg.ImageWords[i])
+			if err != nil {
+				return nil, err
+			}
+			texts[i] = t
+		}
+		return texts, nil
+	}
+
+	if cfg.Text.FontSize != 0 {
+		return texts, nil
+	}
+
+	// All texts share the size of the one taking the most space once rendered
+	size := 0.0
+	for i, iw := range cfg.ImageWords {
+		s, err := g.maxFontSize(iw, texts[i].lines, g.cellW, g.cellH*cfg.Text.Ratio)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 || s < size {
+			size = s
+		}
+	}
+	for i := range texts {
+		texts[i].size = size
+	}
+	return texts, nil
+}
+
+// fitPictoText returns the largest size for the text of iw to fit in the text band of a cell (text.size being the maximum if provided).
+// If enabled, texts too wide to get the size allowed by the height of the band on one line are wrapped onto two lines.
+func (g *generator) fitPictoText(iw config.ImageWord) (pictoText, error) {
+	cfg := g.cfg
+	maxW, maxH := g.cellW, g.cellH*cfg.Text.Ratio
+	text := iw.Text
+	t := pictoText{lines: strings.Split(text, "\n")}
+
+	size, err := g.maxFontSize(iw, t.lines, maxW, maxH)
+	if err != nil {
+		return t, err
+	}
+	t.size = size
+
+	if cfg.Text.Wrap {
+		if err := g.wrapPictoText(iw, &t, maxW, maxH); err != nil {
+			return t, err
+		}
+	}
+
+	if cfg.Text.FontSize != 0 && t.size > cfg.Text.FontSize {
+		t.size = cfg.Text.FontSize
+	}
+	if t.size < cfg.Text.MinSize {
+		log.Warn().
+			Str("text", text).
+			Float64("size", t.size).
+			Float64("minSize", cfg.Text.MinSize).
+			Msg("Text would be smaller than text.minSize, it may overflow its cell")
+		t.size = cfg.Text.MinSize
+	}
+
+	return t, nil
+}
+
+// wrapPictoText splits the one-line text of t onto two lines if it is limited by the width of the band
+// and two lines let it be printed larger
+func (g *generator) wrapPictoText(iw config.ImageWord, t *pictoText, maxW, maxH float64) error {
+	cfg := g.cfg
+	if len(t.lines) != 1 {
+		return nil
+	}
+	// Size the text would have if it was only limited by the height of the band
+	target, err := g.maxFontSize(iw, t.lines, math.MaxFloat64, maxH)
+	if err != nil {
+		return err
+	}
+	if cfg.Text.FontSize != 0 {
+		target = math.Min(target, cfg.Text.FontSize)
+	}
+	if t.size >= target {
+		return nil
+	}
+
+	lines := splitInTwo(t.lines[0], g.canvas.MeasureTextWidth)
+	if len(lines) != 2 {
+		return nil
+	}
+	size, err := g.maxFontSize(iw, lines, maxW, maxH)
+	if err != nil {
+		return err
+	}
+	if size > t.size {
+		t.size = size
+		t.lines = lines
+	}
+	return nil
+}
+
+// splitInTwo splits text on the space giving the most balanced lines.
+// text is returned as is if it cannot be split.
+func splitInTwo(text string, measure func(string) (float64, error)) []string {
+	best := []string{text}
+	bestW := math.MaxFloat64
+	for i, r := range text {
+		if r != ' ' {
+			continue
+		}
+		// Only one space is removed so that the positions of text colors are kept
+		l1, l2 := text[:i], text[i+1:]
+		if strings.TrimSpace(l1) == "" || strings.TrimSpace(l2) == "" {
+			continue
+		}
+		w1, err1 := measure(l1)
+		w2, err2 := measure(l2)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if w := math.Max(w1, w2); w < bestW {
+			best, bestW = []string{l1, l2}, w
+		}
+	}
+	return best
+}
+
+// printPage prints a page
+func (g *generator) printPage(page int, mode pageMode) error {
+	cfg := g.cfg
+	if err := g.canvas.AddPage(); err != nil {
+		return err
+	}
+
+	// Printer are misaligned when printing two-sided, adding an offset on odd pages to compensate
+	offsetX := float64(0)
+	offsetY := float64(0)
+	if mode == pageModePictos {
+		offsetX = float64(cfg.Page.TwoSidedOffset.X)
+		offsetY = float64(cfg.Page.TwoSidedOffset.Y)
+	}
+
+	if g.opts.CutLines {
+		g.printCutLines(offsetX, offsetY)
+	}
+
+	for l := 0; l < cfg.Page.Lines; l++ {
+		for c := 0; c < cfg.Page.Cols; c++ {
+			idx := page*cfg.Page.Cols*cfg.Page.Lines + cfg.Page.Cols*l + c
+			if idx >= len(cfg.ImageWords) { // no more images
+				return nil
+			}
+
+			x := cfg.Page.PageMargins.Left() + float64(c)*g.cellW + offsetX
+			y := cfg.Page.PageMargins.Top() + float64(l)*g.cellH + offsetY
+			if mode == pageModeDefinitions {
+				x = cfg.Page.PageMargins.Left() + (float64(cfg.Page.Cols)-float64(c)-1)*g.cellW
+			}
+
+			pc := draw.NewPictoCell(
+				cfg.Page.Margins,
+				x,
+				y,
+				g.cellW,
+				g.cellH,
+				cfg.ImageWords[idx],
+			)
+
+			if err := g.printCell(pc, idx, mode); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *generator) printCell(c draw.PictoCell, idx int, mode pageMode) error {
+	cfg := g.cfg
+	if c.Background != nil {
+		g.canvas.FillRect(c.X, c.Y, c.W, c.H, g.opts.ColorMode.color(*c.Background))
+	}
+
+	var cellPrinterFunc cellPrinter
+	switch mode {
+	case pageModePictos:
+		cellPrinterFunc = printCellPicto
+	case pageModeDefinitions:
+		cellPrinterFunc = printCellDefinition
+	}
+	if err := cellPrinterFunc(g, c, idx); err != nil {
+		return err
+	}
+
+	// Borders are drawn last so that they are not covered by backgrounds
+	if mode == pageModePictos || (mode == pageModeDefinitions && (cfg.Text.Definitions.Borders || c.Def.Borders)) {
+		g.canvas.SetLineWidth(1)
+		g.canvas.SetLineType("")
+		borderColor := cfg.Page.BorderColor
+		if c.BorderColor != nil {
+			borderColor = *c.BorderColor
+		}
+		g.canvas.SetLineColor(g.opts.ColorMode.color(borderColor))
+		g.canvas.RectFromUpperLeft(c.X, c.Y, c.W, c.H)
+	}
+	return nil
+}
+
+// printCellPicto prints a cell with a picto and a text on top or bottom
+func printCellPicto(g *generator, c draw.PictoCell, idx int) error {
+	cfg := g.cfg
+	text := g.texts[idx]
+	fontSize := text.size
+	if err := g.canvas.SetFont(fontFamilyNameText, fontSize); err != nil {
+		return fmt.Errorf("unable to enable font: %w", err)
+	}
+
+	cellTextHeightPt := c.H * cfg.Text.Ratio
+
+	// Depending on the font, this does not take into account "high/low" letters (e.g. f,g,y,t,l etc.)
+	textHeight := gopdf.ContentObjCalTextHeightPrecise(fontSize)
+	textOffsetY := c.H - cellTextHeightPt/2 + textHeight/2 - cfg.Page.Paddings.Bottom()
+	imageOffsetY := cfg.Page.Paddings.Top()
+	if cfg.Text.Top { // Drawing text on the top of the cell
+		// Lines are centered on the offset, moving them down so that the first one stays in the cell
+		textOffsetY = textHeight + cfg.Page.Paddings.Top() + float64(len(text.lines)-1)*lineHeight(fontSize, pictoLineSpacingRatio)/2
+		imageOffsetY = cellTextHeightPt + cfg.Page.Paddings.Top()
+	}
+
+	if err := g.printCellImage(c, g.pictures[idx], imageOffsetY); err != nil {
+		return err
+	}
+
+	if bg := cfg.Text.Background; bg != nil {
+		bandY := c.Y + c.H - cellTextHeightPt
+		if cfg.Text.Top {
+			bandY = c.Y
+		}
+		g.canvas.FillRect(c.X, bandY, c.W, cellTextHeightPt, g.opts.ColorMode.color(*bg))
+	}
+
+	ptwcX := c.X + c.W/2
+	ptwcY := c.Y + textOffsetY
+	return g.printTextWithColors(
+		ptwcX,
+		ptwcY,
+		textFont{fontFamilyNameText, fontSize},
+		c.Text,
+		text.lines,
+		pictoLineSpacingRatio,
+		cfg.Text.PictoTextColors(c.ImageWord), c.Styles, cfg.Text.Color,
+		config.TextAlignCenter,
+	)
+}
+
+// printCellImage prints the image of a cell in the area left by the text, depending on its fit mode
+func (g *generator) printCellImage(c draw.PictoCell, pic *picture, imageOffsetY float64) error {
+	cfg := g.cfg
+	w, h := g.imageSize(c, pic.w, pic.h)
+
+	// Centered horizontally and at the top of the area left by the text
+	x := c.X + (c.W-w)/2
+	y := c.Y + imageOffsetY
+	if c.Fit == config.FitCover || c.Fit == config.FitStretch {
+		// Overflow being cropped around the focal point
+		fp := config.DefaultFocalPoint
+		if c.FocalPoint != nil {
+			fp = *c.FocalPoint
+		}
+		areaX := c.X + cfg.Page.Paddings.Left()
+		areaY := c.Y + imageOffsetY
+		areaW, areaH := g.imageArea(c)
+		x = areaX + (areaW-w)*fp.X
+		y = areaY + (areaH-h)*fp.Y
+
+		g.canvas.ClipRect(areaX, areaY, areaW, areaH)
+		defer g.canvas.ResetClip()
+	}
+
+	if err := g.canvas.Image(pic, x, y, w, h); err != nil {
+		return fmt.Errorf("problem creating pdf image %s: %w", c.Image, err)
+	}
+	return nil
+}
+
+// imageArea returns the size of the area left by the text to the image of a cell
+func (g *generator) imageArea(c draw.PictoCell) (float64, float64) {
+	cfg := g.cfg
+	return c.W - cfg.Page.Paddings.LeftRight(), c.H - c.H*cfg.Text.Ratio - cfg.Page.Paddings.TopBottom()
+}
+
+// imageSize returns the size of an image of imgW x imgH pixels once placed in a cell, depending on its fit mode
+func (g *generator) imageSize(c draw.PictoCell, imgW, imgH float64) (float64, float64) {
+	areaW, areaH := g.imageArea(c)
+
+	switch c.Fit {
+	case config.FitStretch:
+		return areaW, areaH
+	case config.FitCover:
+		// Image fills the whole area
+		scale := math.Max(areaW/imgW, areaH/imgH)
+		return imgW * scale, imgH * scale
+	}
+
+	var w, h float64
+	if c.W >= c.H {
+		// Image should fill the height of the cell except if larger than height
+		h = areaH
+		w = imgW * h / imgH
+
+		if w > areaW { // image width is wider than the outer cell
+			w = areaW
+			h = imgH * w / imgW
+		}
+	} else {
+		// Image should fill the width of the cell except if height is more than available space
+		w = areaW
+		h = imgH * w / imgW
+
+		if h > areaH { // image height is higher than the outer cell
+			h = areaH
+			w = imgW * h / imgH
+		}
+	}
+	return w, h
+}
+
+// printCellDefinition prints a cell with a text/definition wrapped and centered
+func printCellDefinition(g *generator, c draw.PictoCell, idx int) error {
+	cfg := g.cfg
+	if strings.Trim(c.Def.Text, " ") == "" {
+		return nil
+	}
+
+	font, err := g.setDefinitionFont(c, g.texts[idx].size)
+	if err != nil {
+		return err
+	}
+
+	defaultColor := cfg.Text.Color
+	if !c.Def.Color.IsBlack() {
+		defaultColor = c.Def.Color
+	}
+
+	lines, err := g.wrapStyledText(font, c.Def.Text, c.Def.Styles, c.W-cfg.Page.Paddings.LeftRight())
+	if err != nil {
+		return fmt.Errorf("unable to word wrap text %.30s...: %w", c.Def.Text, err)
+	}
+
+	if c.Def.LineSpacingRatio == 0 && len(lines) > 1 {
+		log.Warn().
+			Str("text", fmt.Sprintf("%.30s...", c.Def.Text)).
+			Msg("lineSpacingRatio is zero")
+	}
+
+	ptwcX := c.X + c.W/2
+	ptwcY := c.Y + c.H/2
+	if c.Def.Definition.Align == config.TextAlignLeft {
+		ptwcX = c.X
+	}
+	return g.printTextWithColors(
+		ptwcX,
+		ptwcY,
+		font,
+		c.Def.Text,
+		lines,
+		c.Def.LineSpacingRatio,
+		cfg.Text.DefinitionTextColors(c.ImageWord),
+		c.Def.Styles,
+		defaultColor,
+		c.Def.Definition.Align,
+	)
+}
+
+// setDefinitionFont enables the font to use for the definition of c and returns it
+func (g *generator) setDefinitionFont(c draw.PictoCell, fontSize float64) (textFont, error) {
+	newFontSize := g.cfg.Text.Definitions.Size
+	if c.Def.Size > 0 {
+		newFontSize = c.Def.Size
+	}
+	if newFontSize == 0 {
+		newFontSize = fontSize
+	}
+
+	fontFamily := fontFamilyNameDefinitions
+	if c.Def.Font != "" {
+		fontFamily = path.Base(c.Def.Font)
+		if err := addFont(g.canvas, fontFamily, c.Def.Font); err != nil {
+			return textFont{}, fmt.Errorf("unable to use font %s: %w", c.Def.Font, err)
+		}
+		if err := g.addFontVariants(fontFamily, c.Def.FontVariants); err != nil {
+			return textFont{}, err
+		}
+	}
+	font := textFont{fontFamily, newFontSize}
+	if err := g.setFont(font, 0); err != nil {
+		return textFont{}, err
+	}
+
+	return font, nil
+}
+
+// lineHeight returns the height of a line of text, spacing included
+func lineHeight(fontSize, lineSpacingRatio float64) float64 {
+	extraSpaceBetweenLines := fontSize * lineSpacingRatio
+	return gopdf.ContentObjCalTextHeightPrecise(fontSize) + extraSpaceBetweenLines*2
+}
+
+// printTextWithColors prints lines of text that have been wrapped beforehand
+// x should be in the center of the cell so text is centered, the true x will be calculated taken into account the real width of each line
+// y is the y coordinate of the **center of the text block**. Each lines will be spaced depending on line height and the given font size
+// So the whole text will be written so that y is in its center.
+// If there is one line, y is used as is
+// Colors and styles are indexed by grapheme cluster of text, the text before wrapping, so that they do not depend on where lines are wrapped.
+func (g *generator) printTextWithColors(x, y float64, font textFont, text string, textLines []string, lineSpacingRatio float64, colors config.TextColors, styles config.TextStyles, defaultColor config.Color, textAlign config.TextAlign) error {
+	if len(textLines) == 0 {
+		return nil
+	}
+
+	textHeight := lineHeight(font.size, lineSpacingRatio)
+	if len(textLines) > 1 {
+		// printing lines from the bottom left, so we need to actually subtract 1 line which will be printed above cursor
+		y -= (float64(len(textLines)-1) * textHeight) / 2
+	}
+
+	starts := lineStarts(text, textLines)
+	for j, line := range textLines {
+		textWidth, err := g.measureText(font, line, starts[j], styles)
+		if err != nil {
+			return fmt.Errorf("unable to calculate width of %s: %w", line, err)
+		}
+		lineX := x - textWidth/2
+		if textAlign == config.TextAlignLeft {
+			lineX = x
+		}
+		g.canvas.SetXY(lineX, y+float64(j)*textHeight)
+
+		var style config.TextStyle
+		for i, cluster := range config.Graphemes(line) {
+			pos := starts[j] + i
+			color, ok := colors[pos]
+			if !ok {
+				color = defaultColor
+			}
+			if styles[pos] != style {
+				style = styles[pos]
+				if err := g.setFont(font, style); err != nil {
+					return err
+				}
+			}
+
+			if err := g.canvas.Text(cluster, g.opts.ColorMode.color(color)); err != nil {
+				return fmt.Errorf("unable to add char %s to PDF: %w", cluster, err)
+			}
+		}
+		if style != 0 {
+			if err := g.setFont(font, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// printCutLines prints cut lines with an offset on the left (to be able to align two-sided prints horizontally)
+func (g *generator) printCutLines(offsetX, offsetY float64) {
+	cfg := g.cfg
+
+	g.canvas.SetLineWidth(1)
+	g.canvas.SetLineType("dotted")
+	g.canvas.SetLineColor(config.Colors["black"])
+
+	for i := 1; i < cfg.Page.Cols; i++ {
+		x := cfg.Page.PageMargins.Left() + float64(i)*g.cellW + offsetX
+		g.canvas.Line(x, 0, x, g.pageH)
+	}
+
+	for i := 1; i < cfg.Page.Lines; i++ {
+		y := cfg.Page.PageMargins.Top() + float64(i)*g.cellH + offsetY
+		g.canvas.Line(0, y, g.pageW, y)
+	}
+}
+
+// maxFontSize returns the biggest font size for the text of iw, split into lines, to fit in maxWidth x maxHeight
+func (g *generator) maxFontSize(iw config.ImageWord, lines []string, maxWidth, maxHeight float64) (float64, error) {
+	starts := lineStarts(iw.Text, lines)
+	fontSize := 110
+	inc := -1
+	for {
+		fontSize += inc
+
+		if fontSize < int(math.Abs(float64(inc))) {
+			break // no size found
+		}
+
+		if err := g.canvas.SetFont(fontFamilyNameText, float64(fontSize)); err != nil {
+			return 0, fmt.Errorf("unable to enable font: %w", err)
+		}
+
+		textWidth := 0.0
+		for i, line := range lines {
+			w, err := g.measureText(textFont{fontFamilyNameText, float64(fontSize)}, line, starts[i], iw.Styles)
+			if err != nil {
+				return 0, fmt.Errorf("unable to calculate width of %s: %w", line, err)
+			}
+			textWidth = math.Max(textWidth, w)
+		}
+		textHeight := gopdf.ContentObjCalTextHeight(fontSize)
+		if len(lines) > 1 {
+			textHeight = float64(len(lines)) * lineHeight(float64(fontSize), pictoLineSpacingRatio)
+		}
+		if textWidth < maxWidth && textHeight < maxHeight {
+			// Height does not take accents and letters like p, q, etc.
+			// Taking 50% size because why not 🤷‍
+			newFontSize := float64(fontSize) * 0.5
+			fontSize = int(newFontSize)
+			if err := g.canvas.SetFont(fontFamilyNameText, float64(fontSize)); err != nil {
+				return 0, fmt.Errorf("unable to enable font: %w", err)
+			}
+
+			log.Debug().
+				Int("size", fontSize).
+				Msg("Setting font size")
+			break
+		}
+	}
+
+	return float64(fontSize), nil
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	"github.com/nmaupu/gopicto/config"
+	"reflect"
+	"testing"
+)
+
+func TestSplitInTwo(t *testing.T) {
+	// Each character is 1pt wide
+	measure := func(s string) (float64, error) {
+		return float64(len(s)), nil
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "two words", text: "chat noir", want: []string{"chat", "noir"}},
+		{name: "most balanced space", text: "le petit chat", want: []string{"le petit", "chat"}},
+		{name: "one word", text: "hippopotame", want: []string{"hippopotame"}},
+		{name: "leading and trailing spaces", text: " chat ", want: []string{" chat "}},
+		{name: "only one space removed", text: "chat  noir", want: []string{"chat", " noir"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitInTwo(tt.text, measure); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitInTwo() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitPictoText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wrap      bool
+		wantLines int
+	}{
+		{name: "wrap disabled", text: "le chat noir de la voisine", wrap: false, wantLines: 1},
+		{name: "too wide without minSize", text: "le chat noir de la voisine", wrap: true, wantLines: 2},
+		{name: "limited by height", text: "chat", wrap: true, wantLines: 1},
+		{name: "explicit line break", text: "le chat\nnoir", wrap: true, wantLines: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.PDF{
+				Page: config.Page{Cols: 4, Lines: 4},
+				Text: config.Text{Sizing: config.SizingPerCell, Wrap: tt.wrap},
+			}
+			if err := cfg.Init(); err != nil {
+				t.Fatal(err)
+			}
+			g, err := newGenerator(cfg, Options{}, func(pageW, pageH float64) canvas {
+				return newRasterCanvas(pageW, pageH, FormatPNG, DefaultDPI, nil)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := g.fitPictoText(config.ImageWord{Text: tt.text})
+			if err != nil {
+				t.Fatalf("fitPictoText() error = %v", err)
+			}
+			if len(got.lines) != tt.wantLines {
+				t.Errorf("fitPictoText() lines = %q, want %d lines", got.lines, tt.wantLines)
+			}
+		})
+	}
+}
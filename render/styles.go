@@ -0,0 +1,164 @@
+package render
+
+import (
+	"fmt"
+	"github.com/nmaupu/gopicto/config"
+	"github.com/rs/zerolog/log"
+	"strings"
+)
+
+// fontStyles are the styles having a font variant, in the order their fonts are added
+var fontStyles = []config.TextStyle{config.StyleBold, config.StyleItalic, config.StyleBold | config.StyleItalic}
+
+// textFont is the font of a text, the variants of its family being used for the characters styled with markup
+type textFont struct {
+	family string
+	size   float64
+}
+
+// variantFamily returns the name of the family of the variant of family used for style
+func variantFamily(family string, style config.TextStyle) string {
+	switch style {
+	case config.StyleBold:
+		return family + "Bold"
+	case config.StyleItalic:
+		return family + "Italic"
+	case config.StyleBold | config.StyleItalic:
+		return family + "BoldItalic"
+	}
+	return family
+}
+
+// addFontVariants adds the fonts of the variants of family which are provided
+func (g *generator) addFontVariants(family string, variants config.FontVariants) error {
+	for _, style := range fontStyles {
+		font := variants.Variant(style)
+		if font == "" {
+			continue
+		}
+		if err := addFont(g.canvas, variantFamily(family, style), font); err != nil {
+			return fmt.Errorf("unable to use font %s: %w", font, err)
+		}
+		g.variants[variantFamily(family, style)] = true
+	}
+	return nil
+}
+
+// warnMissingFontVariants warns when texts are styled with markup without a font for their style, the regular one being used instead
+func (g *generator) warnMissingFontVariants() {
+	for _, style := range fontStyles {
+		text, def := false, false
+		for _, iw := range g.cfg.ImageWords {
+			text = text || hasStyle(iw.Styles, style)
+			def = def || (iw.Def.Font == "" && hasStyle(iw.Def.Styles, style))
+		}
+		if (text && !g.variants[variantFamily(fontFamilyNameText, style)]) ||
+			(def && !g.variants[variantFamily(fontFamilyNameDefinitions, style)]) {
+			// e.g. fontBold
+			log.Warn().Msgf("Some texts are styled with markup but no %s is provided, using the regular font", variantFamily("font", style))
+		}
+	}
+}
+
+func hasStyle(styles config.TextStyles, style config.TextStyle) bool {
+	for _, s := range styles {
+		if s == style {
+			return true
+		}
+	}
+	return false
+}
+
+// setFont enables the font of the characters styled with style, the regular one if there is no variant for style
+func (g *generator) setFont(f textFont, style config.TextStyle) error {
+	family := variantFamily(f.family, style)
+	if !g.variants[family] {
+		family = f.family
+	}
+	if err := g.canvas.SetFont(family, f.size); err != nil {
+		return fmt.Errorf("unable to enable font: %w", err)
+	}
+	return nil
+}
+
+// measureText returns the width of text, start being the index of its first character in the text styles are indexed on.
+// The regular font of f has to be enabled and is enabled again once done.
+func (g *generator) measureText(f textFont, text string, start int, styles config.TextStyles) (float64, error) {
+	if len(styles) == 0 {
+		return g.canvas.MeasureTextWidth(text)
+	}
+
+	width := 0.0
+	clusters := config.Graphemes(text)
+	for i := 0; i < len(clusters); {
+		// Measuring runs of characters sharing the same style
+		style := styles[start+i]
+		j := i + 1
+		for j < len(clusters) && styles[start+j] == style {
+			j++
+		}
+		if err := g.setFont(f, style); err != nil {
+			return 0, err
+		}
+		w, err := g.canvas.MeasureTextWidth(strings.Join(clusters[i:j], ""))
+		if err != nil {
+			return 0, err
+		}
+		width += w
+		i = j
+	}
+	return width, g.setFont(f, 0)
+}
+
+// wrapStyledText splits text into lines not wider than width, on its line breaks first and then between words
+func (g *generator) wrapStyledText(f textFont, text string, styles config.TextStyles, width float64) ([]string, error) {
+	lines := make([]string, 0)
+	start := 0 // index of the first character of the paragraph in text
+	for _, paragraph := range strings.Split(text, "\n") {
+		var wrapped []string
+		var err error
+		switch {
+		case paragraph == "":
+			wrapped = []string{""}
+		case len(styles) == 0:
+			wrapped, err = g.canvas.SplitTextWithWordWrap(paragraph, width)
+		default:
+			wrapped, err = wrapText(paragraph, width, func(line string, offset int) (float64, error) {
+				return g.measureText(f, line, start+len(config.Graphemes(paragraph[:offset])), styles)
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, wrapped...)
+		start += len(config.Graphemes(paragraph)) + 1
+	}
+	return lines, nil
+}
+
+// lineStarts returns the index of the first character of each line in text, lines being text once wrapped.
+// Wrapping removes spaces and line breaks from text, lines are looked for in it to know where they start.
+func lineStarts(text string, lines []string) []int {
+	// Byte offset of each character of text
+	clusters := config.Graphemes(text)
+	offsets := make([]int, len(clusters))
+	for i, offset := 0, 0; i < len(clusters); i++ {
+		offsets[i] = offset
+		offset += len(clusters[i])
+	}
+
+	starts := make([]int, len(lines))
+	pos, offset := 0, 0
+	for j, line := range lines {
+		if i := strings.Index(text[offset:], line); i >= 0 {
+			offset += i
+			for pos < len(offsets) && offsets[pos] < offset {
+				pos++
+			}
+			offset += len(line)
+		}
+		starts[j] = pos
+		pos += len(config.Graphemes(line))
+	}
+	return starts
+}
@@ -0,0 +1,30 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineStarts(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		lines []string
+		want  []int
+	}{
+		{name: "one line", text: "le chat", lines: []string{"le chat"}, want: []int{0}},
+		{name: "wrapped on a space", text: "le chat noir", lines: []string{"le chat", "noir"}, want: []int{0, 8}},
+		{name: "line break", text: "un\nchat", lines: []string{"un", "chat"}, want: []int{0, 3}},
+		{name: "same line twice", text: "chat chat", lines: []string{"chat", "chat"}, want: []int{0, 5}},
+		{name: "several spaces", text: "chat  noir", lines: []string{"chat", " noir"}, want: []int{0, 5}},
+		{name: "combining accents", text: "e\u0301te\u0301 chat", lines: []string{"e\u0301te\u0301", "chat"}, want: []int{0, 4}},
+		{name: "no lines", text: "", lines: []string{}, want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lineStarts(tt.text, tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lineStarts() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
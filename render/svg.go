@@ -0,0 +1,287 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"github.com/nmaupu/gopicto/config"
+	"github.com/rs/zerolog/log"
+	"math"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// svgSpan is a run of characters sharing the same color
+type svgSpan struct {
+	color config.Color
+	text  string
+}
+
+// svgText is a text element being built, consecutive characters printed on the same line being merged together
+type svgText struct {
+	x, y   float64
+	endX   float64
+	family string
+	size   float64
+	spans  []svgSpan
+}
+
+// svgCanvas draws pages as SVG documents and writes each one of them when the next page is added
+type svgCanvas struct {
+	*fontSet
+	pageW      float64
+	pageH      float64
+	linkImages bool
+	newPage    PageWriterFunc
+
+	body      *bytes.Buffer
+	page      int
+	clips     int // number of clipping paths of the page, used to generate their ids
+	clipped   bool
+	fontIDs   map[string]int // index of each font used in the page, fonts being referenced as f0, f1, etc.
+	text      *svgText
+	x, y      float64
+	lineWidth float64
+	lineType  string
+	lineColor config.Color
+}
+
+func newSvgCanvas(pageW, pageH float64, linkImages bool, newPage PageWriterFunc) *svgCanvas {
+	return &svgCanvas{
+		fontSet:    newFontSet(),
+		pageW:      pageW,
+		pageH:      pageH,
+		linkImages: linkImages,
+		newPage:    newPage,
+		lineWidth:  1,
+	}
+}
+
+// num formats a coordinate or a size, a thousandth of a point being precise enough
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+func hexColor(c config.Color) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// paint returns the attributes painting with a color, attr being fill or stroke
+func paint(attr string, c config.Color) string {
+	s := fmt.Sprintf(`%s="%s"`, attr, hexColor(c))
+	if !c.IsOpaque() {
+		s += fmt.Sprintf(` %s-opacity="%s"`, attr, num(c.Opacity()))
+	}
+	return s
+}
+
+func (c *svgCanvas) AddPage() error {
+	if err := c.flush(); err != nil {
+		return err
+	}
+
+	c.page++
+	c.clips = 0
+	c.clipped = false
+	c.body = &bytes.Buffer{}
+	c.fontIDs = make(map[string]int)
+	return nil
+}
+
+// flush writes the current page if any
+func (c *svgCanvas) flush() error {
+	if c.body == nil {
+		return nil
+	}
+	c.ResetClip()
+
+	w, err := c.newPage(c.page)
+	if err != nil {
+		return fmt.Errorf("unable to create page %d: %w", c.page, err)
+	}
+	defer func() {
+		if err := w.Close(); err != nil {
+			log.Error().Err(err).Msg("unable to close page")
+		}
+	}()
+
+	doc := &bytes.Buffer{}
+	doc.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(doc, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%spt" height="%spt" viewBox="0 0 %s %s">`+"\n",
+		num(c.pageW), num(c.pageH), num(c.pageW), num(c.pageH))
+
+	// Embedding fonts so that the page renders the same everywhere
+	families := make([]string, len(c.fontIDs))
+	for family, id := range c.fontIDs {
+		families[id] = family
+	}
+	if len(families) > 0 {
+		doc.WriteString("<defs><style>\n")
+		for id, family := range families {
+			fmt.Fprintf(doc, "@font-face { font-family: '%s'; src: url(data:font/ttf;base64,%s); }\n",
+				svgFontID(id), base64.StdEncoding.EncodeToString(c.data[family]))
+		}
+		doc.WriteString("</style></defs>\n")
+	}
+
+	fmt.Fprintf(doc, `<rect x="0" y="0" width="%s" height="%s" fill="white"/>`+"\n", num(c.pageW), num(c.pageH))
+	doc.Write(c.body.Bytes())
+	doc.WriteString("</svg>\n")
+
+	if _, err := w.Write(doc.Bytes()); err != nil {
+		return fmt.Errorf("unable to write page %d: %w", c.page, err)
+	}
+
+	c.body = nil
+	return nil
+}
+
+func (c *svgCanvas) Close() error {
+	return c.flush()
+}
+
+// ClipRect groups everything drawn until ResetClip is called in a clipped group
+func (c *svgCanvas) ClipRect(x, y, w, h float64) {
+	c.ResetClip()
+	c.clips++
+	c.clipped = true
+	fmt.Fprintf(c.body, `<clipPath id="clip%d"><rect x="%s" y="%s" width="%s" height="%s"/></clipPath>`+"\n",
+		c.clips, num(x), num(y), num(w), num(h))
+	fmt.Fprintf(c.body, `<g clip-path="url(#clip%d)">`+"\n", c.clips)
+}
+
+func (c *svgCanvas) ResetClip() {
+	c.flushText()
+	if c.clipped {
+		c.body.WriteString("</g>\n")
+		c.clipped = false
+	}
+}
+
+func (c *svgCanvas) SetXY(x, y float64) {
+	c.x, c.y = x, y
+}
+
+// Text appends text to the current text element if it follows it, starts a new one otherwise
+func (c *svgCanvas) Text(text string, color config.Color) error {
+	w, err := c.MeasureTextWidth(text)
+	if err != nil {
+		return err
+	}
+
+	t := c.text
+	if t == nil || t.y != c.y || t.endX != c.x || t.family != c.family || t.size != c.size {
+		c.flushText()
+		t = &svgText{x: c.x, y: c.y, family: c.family, size: c.size}
+		c.text = t
+		if _, ok := c.fontIDs[c.family]; !ok {
+			c.fontIDs[c.family] = len(c.fontIDs)
+		}
+	}
+
+	if n := len(t.spans); n > 0 && t.spans[n-1].color.Equals(color) {
+		t.spans[n-1].text += text
+	} else {
+		t.spans = append(t.spans, svgSpan{color: color, text: text})
+	}
+
+	c.x += w
+	t.endX = c.x
+	return nil
+}
+
+// svgFontID returns the name a font is declared with in a page, family names not being safe to write as is
+func svgFontID(id int) string {
+	return fmt.Sprintf("f%d", id)
+}
+
+// flushText writes the text element being built if any
+func (c *svgCanvas) flushText() {
+	t := c.text
+	if t == nil {
+		return
+	}
+	c.text = nil
+
+	fmt.Fprintf(c.body, `<text x="%s" y="%s" font-family="'%s'" font-size="%s" xml:space="preserve">`,
+		num(t.x), num(t.y), svgFontID(c.fontIDs[t.family]), num(t.size))
+	for _, span := range t.spans {
+		fmt.Fprintf(c.body, `<tspan %s>`, paint("fill", span.color))
+		_ = xml.EscapeText(c.body, []byte(span.text))
+		c.body.WriteString("</tspan>")
+	}
+	c.body.WriteString("</text>\n")
+}
+
+func (c *svgCanvas) SetLineWidth(width float64) {
+	c.lineWidth = width
+}
+
+func (c *svgCanvas) SetLineType(lineType string) {
+	c.lineType = lineType
+}
+
+// strokeAttrs returns the attributes of the current line style
+func (c *svgCanvas) strokeAttrs() string {
+	attrs := fmt.Sprintf(`%s stroke-width="%s"`, paint("stroke", c.lineColor), num(c.lineWidth))
+	if c.lineType == "dotted" {
+		attrs += fmt.Sprintf(` stroke-dasharray="%s"`, num(dottedLinePattern))
+	}
+	return attrs
+}
+
+func (c *svgCanvas) SetLineColor(color config.Color) {
+	c.lineColor = color
+}
+
+func (c *svgCanvas) Line(x1, y1, x2, y2 float64) {
+	c.flushText()
+	fmt.Fprintf(c.body, `<line x1="%s" y1="%s" x2="%s" y2="%s" %s/>`+"\n",
+		num(x1), num(y1), num(x2), num(y2), c.strokeAttrs())
+}
+
+func (c *svgCanvas) RectFromUpperLeft(x, y, w, h float64) {
+	c.flushText()
+	fmt.Fprintf(c.body, `<rect x="%s" y="%s" width="%s" height="%s" fill="none" %s/>`+"\n",
+		num(x), num(y), num(w), num(h), c.strokeAttrs())
+}
+
+func (c *svgCanvas) FillRect(x, y, w, h float64, color config.Color) {
+	c.flushText()
+	fmt.Fprintf(c.body, `<rect x="%s" y="%s" width="%s" height="%s" %s/>`+"\n",
+		num(x), num(y), num(w), num(h), paint("fill", color))
+}
+
+// Image links the image file if images are linked, embeds it otherwise.
+// Images which have been modified (e.g. rotated) are always embedded and SVG images are kept as vectors if possible.
+func (c *svgCanvas) Image(pic *picture, x, y, w, h float64) error {
+	c.flushText()
+
+	var href string
+	if c.linkImages && (!pic.modified || pic.svg != nil) {
+		abs, err := filepath.Abs(pic.path)
+		if err != nil {
+			return err
+		}
+		// Windows paths (C:\...) need a leading slash to be valid file URIs
+		path := filepath.ToSlash(abs)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		href = (&url.URL{Scheme: "file", Path: path}).String()
+	} else if pic.svg != nil {
+		href = fmt.Sprintf("data:image/svg+xml;base64,%s", base64.StdEncoding.EncodeToString(pic.svg))
+	} else {
+		href = fmt.Sprintf("data:%s;base64,%s", http.DetectContentType(pic.data), base64.StdEncoding.EncodeToString(pic.data))
+	}
+
+	fmt.Fprintf(c.body, `<image x="%s" y="%s" width="%s" height="%s" preserveAspectRatio="none" xlink:href="`,
+		num(x), num(y), num(w), num(h))
+	_ = xml.EscapeText(c.body, []byte(href))
+	c.body.WriteString("\"/>\n")
+	return nil
+}
@@ -0,0 +1,156 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"github.com/nmaupu/gopdf"
+	"github.com/nmaupu/gopicto/config"
+	"github.com/nmaupu/gopicto/draw"
+	"io/ioutil"
+	"strings"
+)
+
+// Validate checks that cfg can be rendered and returns all the problems found.
+// cfg is expected to be initialized (see config.PDF.Init).
+func Validate(ctx context.Context, cfg config.PDF) []config.Problem {
+	problems, ok := validateResources(ctx, cfg)
+	// Text measurements are only possible with valid fonts
+	if !ok {
+		return problems
+	}
+
+	g, err := newGenerator(cfg, Options{}, func(pageW, pageH float64) canvas {
+		return newPdfCanvas(gopdf.Rect{W: pageW, H: pageH}, ioutil.Discard)
+	})
+	if err != nil {
+		return append(problems, config.Problem{Message: err.Error()})
+	}
+
+	return append(problems, g.checkTextsFit()...)
+}
+
+// ValidateResources checks that the images and the fonts of cfg can be loaded and returns all the problems found.
+// Unlike Validate, cfg does not need to be successfully initialized.
+func ValidateResources(ctx context.Context, cfg config.PDF) []config.Problem {
+	problems, _ := validateResources(ctx, cfg)
+	return problems
+}
+
+// validateResources checks the images and the fonts of cfg, returning whether its fonts and the context are valid
+func validateResources(ctx context.Context, cfg config.PDF) ([]config.Problem, bool) {
+	problems := make([]config.Problem, 0)
+
+	picOpts := newPictureOptions(cfg, Options{})
+	for i, iw := range cfg.ImageWords {
+		if err := ctx.Err(); err != nil {
+			return append(problems, config.Problem{Message: err.Error()}), false
+		}
+
+		p := config.ImageWordPath(i, iw, "image")
+		if iw.Image == "" {
+			problems = append(problems, config.Problem{Path: p, Message: "image is not provided"})
+			continue
+		}
+		if _, err := loadPicture(iw, picOpts); err != nil {
+			problems = append(problems, config.Problem{Path: p, Message: fmt.Sprintf("unable to read image %s: %s", iw.Image, err)})
+		}
+	}
+
+	// Fonts are checked one by one so that each problem is reported with its own path
+	fonts := []struct{ path, font string }{
+		{"text.font", cfg.Text.Font},
+		{"text.fontBold", cfg.Text.FontBold},
+		{"text.fontItalic", cfg.Text.FontItalic},
+		{"text.fontBoldItalic", cfg.Text.FontBoldItalic},
+		{"text.definitions.font", cfg.Text.Definitions.Font},
+		{"text.definitions.fontBold", cfg.Text.Definitions.FontBold},
+		{"text.definitions.fontItalic", cfg.Text.Definitions.FontItalic},
+		{"text.definitions.fontBoldItalic", cfg.Text.Definitions.FontBoldItalic},
+	}
+	for i, iw := range cfg.ImageWords {
+		fonts = append(fonts,
+			struct{ path, font string }{config.ImageWordPath(i, iw, "def.font"), iw.Def.Font},
+			struct{ path, font string }{config.ImageWordPath(i, iw, "def.fontBold"), iw.Def.FontBold},
+			struct{ path, font string }{config.ImageWordPath(i, iw, "def.fontItalic"), iw.Def.FontItalic},
+			struct{ path, font string }{config.ImageWordPath(i, iw, "def.fontBoldItalic"), iw.Def.FontBoldItalic},
+		)
+	}
+	fontProblems := make([]config.Problem, 0)
+	for _, f := range fonts {
+		if f.font == "" {
+			continue
+		}
+		if err := checkFont(f.font); err != nil {
+			fontProblems = append(fontProblems, config.Problem{Path: f.path, Message: fmt.Sprintf("unable to load font %s: %s", f.font, err)})
+		}
+	}
+	return append(problems, fontProblems...), len(fontProblems) == 0
+}
+
+// checkFont checks that a font can be loaded
+func checkFont(font string) error {
+	return addFont(newPdfCanvas(*gopdf.PageSizeA4, ioutil.Discard), "check", font)
+}
+
+// checkTextsFit checks that picto texts and definitions fit in their cell
+func (g *generator) checkTextsFit() []config.Problem {
+	cfg := g.cfg
+	problems := make([]config.Problem, 0)
+
+	texts, err := g.pictoTexts()
+	if err != nil {
+		return append(problems, config.Problem{Path: "text", Message: err.Error()})
+	}
+	if cfg.Text.Sizing != config.SizingPerCell && len(texts) > 0 && texts[0].size < 1 {
+		problems = append(problems, config.Problem{Path: "text", Message: "no font size is small enough for texts to fit in cells"})
+	}
+
+	for i, iw := range cfg.ImageWords {
+		c := draw.NewPictoCell(cfg.Page.Margins, 0, 0, g.cellW, g.cellH, iw)
+		fontSize := texts[i].size
+
+		font := textFont{fontFamilyNameText, fontSize}
+		if err := g.setFont(font, 0); err != nil {
+			return append(problems, config.Problem{Path: "text.font", Message: err.Error()})
+		}
+		starts := lineStarts(iw.Text, texts[i].lines)
+		for j, line := range texts[i].lines {
+			textWidth, err := g.measureText(font, line, starts[j], iw.Styles)
+			if err != nil {
+				problems = append(problems, config.Problem{Path: config.ImageWordPath(i, iw, "text"), Message: err.Error()})
+				break
+			}
+			if available := c.W - cfg.Page.Paddings.LeftRight(); textWidth > available {
+				problems = append(problems, config.Problem{
+					Path:    config.ImageWordPath(i, iw, "text"),
+					Message: fmt.Sprintf("text is too wide for its cell (%.1fpt > %.1fpt at size %.1f)", textWidth, available, fontSize),
+				})
+				break
+			}
+		}
+
+		if strings.Trim(iw.Def.Text, " ") == "" {
+			continue
+		}
+		p := config.ImageWordPath(i, iw, "def.text")
+		defFont, err := g.setDefinitionFont(c, fontSize)
+		if err != nil {
+			problems = append(problems, config.Problem{Path: p, Message: err.Error()})
+			continue
+		}
+		defFontSize := defFont.size
+		lines, err := g.wrapStyledText(defFont, iw.Def.Text, iw.Def.Styles, c.W-cfg.Page.Paddings.LeftRight())
+		if err != nil {
+			problems = append(problems, config.Problem{Path: p, Message: fmt.Sprintf("unable to word wrap text: %s", err)})
+			continue
+		}
+		if textHeight := float64(len(lines)) * lineHeight(defFontSize, iw.Def.LineSpacingRatio); textHeight > c.H {
+			problems = append(problems, config.Problem{
+				Path:    p,
+				Message: fmt.Sprintf("text overflows its cell (%d lines, %.1fpt > %.1fpt at size %.1f)", len(lines), textHeight, c.H, defFontSize),
+			})
+		}
+	}
+
+	return problems
+}
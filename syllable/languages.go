@@ -0,0 +1,69 @@
+package syllable
+
+func init() {
+	Register("fr", french)
+	Register("en", english)
+	Register("es", spanish)
+}
+
+// french splits words into written syllables as taught in French schools (e.g. cho-co-lat, ta-ble, mon-tagne)
+var french = rules{
+	vowel: func(w []rune, i int) bool {
+		c := w[i]
+		switch {
+		case c == 'u' && i > 0 && isOneOf(w[i-1], "qg") && i+1 < len(w) && isOneOf(w[i+1], "aeiouyéèêëîï"):
+			// u of qu and gu is not pronounced (e.g. quille, guêpe)
+			return false
+		case c == 'y' && i > 0 && i+1 < len(w) && isOneOf(w[i-1], frenchVowels) && isOneOf(w[i+1], frenchVowels):
+			// y between vowels is a consonant (e.g. crayon)
+			return false
+		}
+		return isOneOf(c, frenchVowels)
+	},
+	onsets: onsets("bl cl fl gl pl br cr dr fr gr pr tr vr ch ph th gn"),
+}
+
+const frenchVowels = "aeiouyàâäéèêëîïôöùûüÿæœ"
+
+// english splits words with simple rules, English syllables depending mostly on pronunciation
+var english = rules{
+	vowel: func(w []rune, i int) bool {
+		c := w[i]
+		switch {
+		case c == 'e' && i == len(w)-1 && i >= 2 && !isOneOf(w[i-1], englishVowels) && !(w[i-1] == 'l' && !isOneOf(w[i-2], englishVowels)):
+			// silent final e (e.g. make) except in consonant-le (e.g. table)
+			return false
+		case c == 'y' && (i == 0 || i+1 < len(w) && isOneOf(w[i+1], "aeiou")):
+			// y starting a word or followed by a vowel is a consonant (e.g. yes, beyond)
+			return false
+		case c == 'u' && i > 0 && w[i-1] == 'q':
+			return false
+		}
+		return isOneOf(c, englishVowels)
+	},
+	onsets: onsets("bl br cl cr dr fl fr gl gr pl pr tr ch sh th ph wh wr"),
+}
+
+const englishVowels = "aeiouy"
+
+// spanish splits words according to the rules of the Real Academia Española, strong vowels (a, e, o)
+// and accented weak vowels (í, ú) forming a hiatus (e.g. le-er, rí-o)
+var spanish = rules{
+	vowel: func(w []rune, i int) bool {
+		c := w[i]
+		switch {
+		case c == 'u' && i > 0 && isOneOf(w[i-1], "qg") && i+1 < len(w) && isOneOf(w[i+1], "eéií"):
+			// u of que, qui, gue and gui is silent
+			return false
+		case c == 'y':
+			// y is a vowel at the end of a word only (e.g. rey)
+			return i == len(w)-1 && i > 0
+		}
+		return isOneOf(c, "aeiouáéíóúü")
+	},
+	onsets: onsets("bl cl fl gl pl kl br cr dr fr gr pr tr kr ch ll rr"),
+	hiatus: func(a, b rune) bool {
+		strong := func(c rune) bool { return isOneOf(c, "aeoáéóíú") }
+		return strong(a) && strong(b) || a == b
+	},
+}
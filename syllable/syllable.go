@@ -0,0 +1,181 @@
+// Package syllable splits words into written syllables, e.g. to color them alternately for early readers
+package syllable
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Splitter splits a word into syllables
+type Splitter interface {
+	// Split returns the syllables of word, joining them giving word back
+	Split(word string) []string
+}
+
+// DefaultLanguage is the language used when none is provided
+const DefaultLanguage = "fr"
+
+var splitters = map[string]Splitter{}
+
+// Register makes a splitter available for a language (e.g. fr), replacing the one registered if any
+func Register(lang string, s Splitter) {
+	splitters[strings.ToLower(lang)] = s
+}
+
+// ForLanguage returns the splitter of a language
+func ForLanguage(lang string) (Splitter, error) {
+	s, ok := splitters[strings.ToLower(lang)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported language %s (supported: %s)", lang, strings.Join(Languages(), ", "))
+	}
+	return s, nil
+}
+
+// Languages returns the languages having a splitter
+func Languages() []string {
+	langs := make([]string, 0, len(splitters))
+	for lang := range splitters {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// rules are the syllabification rules of a language.
+// Words are split between groups of vowels, the consonants in between being shared between syllables:
+// a single consonant starts the next syllable, the last consonant of a group starts it otherwise
+// unless the last two consonants form an onset which cannot be split (e.g. br, ch).
+type rules struct {
+	// vowel returns true if the i-th letter of the lowercased word is a vowel
+	vowel func(word []rune, i int) bool
+	// onsets are the pairs of consonants which cannot be split and start a syllable
+	onsets map[string]bool
+	// hiatus returns true if two consecutive vowels belong to different syllables
+	hiatus func(a, b rune) bool
+}
+
+func (r rules) Split(word string) []string {
+	runes := []rune(word)
+	lower := make([]rune, len(runes))
+	for i, c := range runes {
+		lower[i] = unicode.ToLower(c)
+	}
+
+	// Combining marks (e.g. accents of decomposed letters) belong to the letter they follow
+	vowel := make([]bool, len(lower))
+	for i := range lower {
+		if unicode.Is(unicode.Mn, lower[i]) && i > 0 {
+			vowel[i] = vowel[i-1]
+		} else {
+			vowel[i] = r.vowel(lower, i)
+		}
+	}
+
+	// Index of the first letter of each syllable, the first one starting the word
+	starts := []int{0}
+	nucleus := false // the previous letter is a vowel of a nucleus
+	lastNucleusEnd := -1
+	for i := range lower {
+		if !vowel[i] {
+			nucleus = false
+			continue
+		}
+		if nucleus {
+			if r.hiatus != nil && !unicode.Is(unicode.Mn, lower[i]) && r.hiatus(lower[i-1], lower[i]) {
+				starts = append(starts, i)
+			}
+			lastNucleusEnd = i + 1
+			continue
+		}
+
+		if lastNucleusEnd >= 0 {
+			// Consonants between the previous nucleus and this one
+			n := i - lastNucleusEnd
+			brk := i
+			if n > 0 {
+				brk = i - 1
+				if n >= 2 && r.onsets[string(lower[i-2:i])] {
+					brk = i - 2
+				}
+			}
+			starts = append(starts, brk)
+		}
+		nucleus = true
+		lastNucleusEnd = i + 1
+	}
+
+	syllables := make([]string, 0, len(starts))
+	for k, start := range starts {
+		end := len(runes)
+		if k+1 < len(starts) {
+			end = starts[k+1]
+		}
+		syllables = append(syllables, string(runes[start:end]))
+	}
+	return syllables
+}
+
+// isOneOf returns true if the letter is one of letters
+func isOneOf(c rune, letters string) bool {
+	return strings.ContainsRune(letters, c)
+}
+
+// onsets returns a set of onsets given as a space separated list
+func onsets(list string) map[string]bool {
+	set := map[string]bool{}
+	for _, o := range strings.Fields(list) {
+		set[o] = true
+	}
+	return set
+}
+
+// Syllables returns the syllables of the words of text as [start, end) rune indexes,
+// words being split by s unless marks (rune indexes of manual breaks) are found in them
+func Syllables(text string, s Splitter, marks []int) [][2]int {
+	runes := []rune(text)
+	syllables := make([][2]int, 0)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes, i) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && isWordRune(runes, i) {
+			i++
+		}
+
+		var breaks []int
+		for _, m := range marks {
+			if m > start && m < i {
+				breaks = append(breaks, m)
+			}
+		}
+		if breaks == nil {
+			pos := start
+			for _, syl := range s.Split(string(runes[start:i])) {
+				if pos > start {
+					breaks = append(breaks, pos)
+				}
+				pos += len([]rune(syl))
+			}
+		}
+
+		for _, b := range breaks {
+			syllables = append(syllables, [2]int{start, b})
+			start = b
+		}
+		syllables = append(syllables, [2]int{start, i})
+	}
+	return syllables
+}
+
+// isWordRune returns true if the i-th rune is part of a word: letters, combining marks
+// and apostrophes between letters (e.g. l'école)
+func isWordRune(runes []rune, i int) bool {
+	if unicode.IsLetter(runes[i]) || unicode.Is(unicode.Mn, runes[i]) {
+		return true
+	}
+	return isOneOf(runes[i], "'’") && i > 0 && i+1 < len(runes) && unicode.IsLetter(runes[i-1]) && unicode.IsLetter(runes[i+1])
+}
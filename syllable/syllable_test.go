@@ -0,0 +1,47 @@
+package syllable
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		lang  string
+		words string // words and their expected syllables separated by -
+	}{
+		{lang: "fr", words: "cho-co-lat ta-ble mon-ta-gne ar-bre é-co-le pa-pa chat quil-le cra-yon Ma-man l'é-co-le"},
+		{lang: "en", words: "ta-ble ba-na-na rab-bit make sis-ter pa-per yel-low"},
+		{lang: "es", words: "ca-sa pe-rro chi-co le-er rí-o ha-blar rey gui-ta-rra ca-lle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			s, err := ForLanguage(tt.lang)
+			if err != nil {
+				t.Fatalf("ForLanguage() error = %v", err)
+			}
+			for _, expected := range strings.Fields(tt.words) {
+				want := strings.Split(expected, "-")
+				if got := s.Split(strings.ReplaceAll(expected, "-", "")); !reflect.DeepEqual(got, want) {
+					t.Errorf("Split(%s) = %v, want %v", strings.ReplaceAll(expected, "-", ""), got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestForLanguage_unsupported(t *testing.T) {
+	if _, err := ForLanguage("xx"); err == nil {
+		t.Errorf("ForLanguage() expected an error for an unsupported language")
+	}
+}
+
+func TestSyllables(t *testing.T) {
+	s, _ := ForLanguage("fr")
+	got := Syllables("Le chocolat, c'est bon", s, []int{6})
+	want := [][2]int{{0, 2}, {3, 6}, {6, 11}, {13, 18}, {19, 22}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Syllables() = %v, want %v", got, want)
+	}
+}